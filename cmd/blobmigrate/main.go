@@ -0,0 +1,126 @@
+// Command blobmigrate copies every object of one blob store to another,
+// keeping keys, so the file IDs saved with comments and documents stay
+// valid after BLOB_STORE is switched. Both stores are configured from the
+// same environment as the API; see helper.NewBlobStore.
+//
+//	go run ./cmd/blobmigrate -from gridfs -to s3
+//
+// Objects already present in the target are skipped unless -overwrite is
+// given. A copy whose SHA-256 differs from the source is removed again and
+// counted as failed.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-todolist/configs"
+	helper "go-todolist/helpers"
+	"log"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+func main() {
+	from := flag.String("from", "", "source blob store: gridfs, s3 or local")
+	to := flag.String("to", "", "target blob store: gridfs, s3 or local")
+	dryRun := flag.Bool("dry-run", false, "list what would be copied without copying")
+	overwrite := flag.Bool("overwrite", false, "copy objects the target already has")
+	flag.Parse()
+	if *from == "" || *to == "" || *from == *to {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var storeDoc *gridfs.Bucket
+	if strings.EqualFold(*from, "gridfs") || strings.EqualFold(*to, "gridfs") {
+		client, _, err := configs.InitMongo()
+		if err != nil {
+			log.Fatalf("Failed to connect to MongoDB: %v", err)
+		}
+		defer client.Disconnect(context.Background())
+		if storeDoc, err = configs.InitGridFSBucket(client, "StoreDoc"); err != nil {
+			log.Fatalf("Failed to open StoreDoc: %v", err)
+		}
+	}
+	source, err := helper.NewBlobStore(*from, storeDoc)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *from, err)
+	}
+	target, err := helper.NewBlobStore(*to, storeDoc)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *to, err)
+	}
+
+	ctx := context.Background()
+	var copied, skipped, failed int
+	err = source.Walk(ctx, func(info helper.BlobInfo) error {
+		if !*overwrite {
+			existing, err := target.Open(ctx, info.Key)
+			if err == nil {
+				existing.Close()
+				skipped++
+				return nil
+			}
+			if !errors.Is(err, helper.ErrBlobNotFound) {
+				log.Printf("%s: checking target: %v", info.Key, err)
+				failed++
+				return nil
+			}
+		}
+		if *dryRun {
+			fmt.Printf("would copy %s (%s, %d bytes)\n", info.Key, info.Name, info.Size)
+			copied++
+			return nil
+		}
+		if err := copyBlob(ctx, source, target, info, *overwrite); err != nil {
+			log.Printf("%s: %v", info.Key, err)
+			failed++
+			return nil
+		}
+		fmt.Printf("copied %s (%s, %d bytes)\n", info.Key, info.Name, info.Size)
+		copied++
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to list %s: %v", *from, err)
+	}
+	fmt.Printf("%d copied, %d skipped, %d failed\n", copied, skipped, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// copyBlob streams one object from source to target under the same key,
+// replacing the target's copy when overwrite is set.
+func copyBlob(ctx context.Context, source, target helper.BlobStore, info helper.BlobInfo, overwrite bool) error {
+	blob, err := source.Open(ctx, info.Key)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	if overwrite {
+		if err := target.Delete(ctx, info.Key); err != nil && !errors.Is(err, helper.ErrBlobNotFound) {
+			return err
+		}
+	}
+	stored, err := target.Put(ctx, helper.BlobInfo{
+		Key:         info.Key,
+		Name:        info.Name,
+		ContentType: info.ContentType,
+		SHA256:      info.SHA256,
+	}, blob)
+	if err != nil {
+		return err
+	}
+	if info.SHA256 != "" && stored.SHA256 != info.SHA256 {
+		if err := target.Delete(ctx, info.Key); err != nil {
+			log.Printf("%s: removing bad copy: %v", info.Key, err)
+		}
+		return fmt.Errorf("sha256 %s does not match the source's %s", stored.SHA256, info.SHA256)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package configs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	helper "go-todolist/helpers"
+)
+
+var (
+	MONGODB_URL   = helper.GodotEnv("Mongodb_Url")
+	DATABASE_NAME = helper.GodotEnv("DataBaseName")
+)
+
+// MongoPoolStats counts the connections of the MongoDB client as reported
+// by its pool monitor.
+type MongoPoolStats struct {
+	open           atomic.Int64
+	inUse          atomic.Int64
+	created        atomic.Int64
+	closed         atomic.Int64
+	checkoutFailed atomic.Int64
+}
+
+// MongoPoolSnapshot is MongoPoolStats at one moment.
+type MongoPoolSnapshot struct {
+	Open           int64 `json:"open"`
+	InUse          int64 `json:"in_use"`
+	Created        int64 `json:"created_total"`
+	Closed         int64 `json:"closed_total"`
+	CheckoutFailed int64 `json:"checkout_failed_total"`
+}
+
+func (s *MongoPoolStats) event(e *event.PoolEvent) {
+	switch e.Type {
+	case event.ConnectionCreated:
+		s.created.Add(1)
+		s.open.Add(1)
+	case event.ConnectionClosed:
+		s.closed.Add(1)
+		s.open.Add(-1)
+	case event.GetSucceeded:
+		s.inUse.Add(1)
+	case event.ConnectionReturned:
+		s.inUse.Add(-1)
+	case event.GetFailed:
+		s.checkoutFailed.Add(1)
+	}
+}
+
+func (s *MongoPoolStats) Snapshot() MongoPoolSnapshot {
+	return MongoPoolSnapshot{
+		Open:           s.open.Load(),
+		InUse:          s.inUse.Load(),
+		Created:        s.created.Load(),
+		Closed:         s.closed.Load(),
+		CheckoutFailed: s.checkoutFailed.Load(),
+	}
+}
+
+// InitMongo creates the MongoDB client shared by every request. The driver
+// dials lazily, so a server that is down only fails the ping.
+func InitMongo() (*mongo.Client, *MongoPoolStats, error) {
+	stats := &MongoPoolStats{}
+	client, err := mongo.Connect(context.Background(), options.Client().
+		ApplyURI(MONGODB_URL).
+		SetPoolMonitor(&event.PoolMonitor{Event: stats.event}))
+	if err != nil {
+		fmt.Print("Error connecting to MongoDB : error=", err)
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		fmt.Print("Error connecting to MongoDB : error=", err)
+	} else {
+		fmt.Println("MongoDB Connected")
+	}
+	return client, stats, nil
+}
+
+// InitGridFSBucket opens a GridFS bucket of DataBaseName.
+func InitGridFSBucket(client *mongo.Client, name string) (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(client.Database(DATABASE_NAME), options.GridFSBucket().SetName(name))
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAssignmentAnalytics godoc
+// @Summary Aggregate assignment history
+// @Description Hold time per person, how often tasks change hands and which groups reassign most, for tasks created between start_date and end_date (default the last 90 days).
+// @Tags Analytics
+// @Produce json
+// @Param departemen query string false "Department"
+// @Param start_date query string false "From date (YYYY-MM-DD)"
+// @Param end_date query string false "To date (YYYY-MM-DD), inclusive"
+// @Param top query int false "Number of most bounced tasks to list (default 20)"
+// @Success 200 {object} helper.AssignmentAnalytics
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/GetAssignmentAnalytics [get]
+func (repository *InitRepo) GetAssignmentAnalytics(c *gin.Context) {
+	var Parameter models.ParamAssignmentAnalytics
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := end.AddDate(0, 0, -90)
+	var err error
+	if Parameter.Start_Date != "" {
+		if start, err = time.ParseInLocation("2006-01-02", Parameter.Start_Date, time.Local); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be YYYY-MM-DD"})
+			return
+		}
+	}
+	if Parameter.End_Date != "" {
+		if end, err = time.ParseInLocation("2006-01-02", Parameter.End_Date, time.Local); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be YYYY-MM-DD"})
+			return
+		}
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+		return
+	}
+	if Parameter.Top <= 0 {
+		Parameter.Top = 20
+	}
+
+	var rows []models.AssignHistoryRow
+	err = repository.DbPg.Raw(models.QueryGetAssignHistoryRange, Parameter.Departemen, Parameter.Departemen, start, end.AddDate(0, 0, 1)).Scan(&rows).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	spans := make([]helper.AssignmentSpan, 0, len(rows))
+	for _, row := range rows {
+		helper.LocalWallClocks(&row.Start_Date, row.End_Date)
+		spans = append(spans, helper.AssignmentSpan{
+			TaskID:       row.Task_ID,
+			Group:        row.Group_Assign,
+			Assignee:     row.User_Assign_To,
+			AssigneeName: row.Emp_Name,
+			Start:        row.Start_Date,
+			End:          row.End_Date,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data": gin.H{
+			"departemen": Parameter.Departemen,
+			"start_date": start.Format("2006-01-02"),
+			"end_date":   end.Format("2006-01-02"),
+			"analytics":  helper.AnalyzeAssignments(spans, now, Parameter.Top),
+		},
+	})
+}
@@ -0,0 +1,442 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// loadBusinessCalendar builds the working-day calendar of a department from
+// the working pattern, the public holidays and the department's closures.
+func (repository *InitRepo) loadBusinessCalendar(departemen string) (*helper.BusinessCalendar, error) {
+	var pattern []models.CalendarWorkingPattern
+	if err := repository.DbPg.Find(&pattern).Error; err != nil {
+		return nil, err
+	}
+	week := helper.DefaultWorkingWeek()
+	for _, day := range pattern {
+		if day.Weekday < 0 || day.Weekday > 6 {
+			continue
+		}
+		start, errStart := helper.ParseClock(day.StartTime)
+		end, errEnd := helper.ParseClock(day.EndTime)
+		if errStart != nil || errEnd != nil {
+			start, end = week[day.Weekday].Start, week[day.Weekday].End
+		}
+		week[day.Weekday] = helper.WorkingHours{Working: day.IsWorking, Start: start, End: end}
+	}
+	cal := helper.NewBusinessCalendar(week)
+
+	var holidays []models.CalendarHoliday
+	if err := repository.DbPg.Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	for _, holiday := range holidays {
+		cal.AddHoliday(inLocal(holiday.Date), holiday.Name)
+	}
+
+	if departemen != "" {
+		var closures []models.CalendarDepartmentClosure
+		if err := repository.DbPg.Where("departemen = ?", departemen).Find(&closures).Error; err != nil {
+			return nil, err
+		}
+		for _, closure := range closures {
+			cal.AddClosure(inLocal(closure.StartDate), inLocal(closure.EndDate), closure.Reason)
+		}
+	}
+	return cal, nil
+}
+
+// reminderDate counts the reminder back from the end date in working days.
+// When the calendar cannot be loaded it falls back to calendar days.
+func (repository *InitRepo) reminderDate(departemen string, endDate time.Time, days int) time.Time {
+	cal, err := repository.loadBusinessCalendar(departemen)
+	if err != nil {
+		log.Printf("Failed to load business calendar, using calendar days: %v", err)
+		return endDate.AddDate(0, 0, -days)
+	}
+	return cal.AddWorkingDays(cal.PreviousWorkingDay(endDate), -days)
+}
+
+// inLocal re-reads a date column in the server's local time zone.
+func inLocal(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// GetWorkingPattern godoc
+// @Summary Get the weekly working pattern
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} models.CalendarWorkingPattern
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/GetWorkingPattern [get]
+func (repository *InitRepo) GetWorkingPattern(c *gin.Context) {
+	var stored []models.CalendarWorkingPattern
+	if err := repository.DbPg.Find(&stored).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	Value := make([]models.CalendarWorkingPattern, 7)
+	for weekday, hours := range helper.DefaultWorkingWeek() {
+		Value[weekday] = models.CalendarWorkingPattern{Weekday: weekday, IsWorking: hours.Working, StartTime: "08:00", EndTime: "17:00"}
+	}
+	for _, day := range stored {
+		if day.Weekday >= 0 && day.Weekday <= 6 {
+			Value[day.Weekday] = day
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// UpdatingWorkingPattern godoc
+// @Summary Update the weekly working pattern
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param file body models.ParamWorkingPattern true "Working pattern"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/UpdatingWorkingPattern [post]
+func (repository *InitRepo) UpdatingWorkingPattern(c *gin.Context) {
+	var Parameter models.ParamWorkingPattern
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i, day := range Parameter.Pattern {
+		if day.Weekday < 0 || day.Weekday > 6 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("weekday %d must be between 0 (Sunday) and 6 (Saturday)", day.Weekday)})
+			return
+		}
+		start, errStart := helper.ParseClock(day.StartTime)
+		end, errEnd := helper.ParseClock(day.EndTime)
+		if errStart != nil || errEnd != nil || end <= start {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("weekday %d needs a start_time before its end_time (HH:MM)", day.Weekday)})
+			return
+		}
+		Parameter.Pattern[i].UpdatedBy = Parameter.Userid
+	}
+	if len(Parameter.Pattern) > 0 {
+		if err := repository.DbPg.Save(&Parameter.Pattern).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// GetHolidays godoc
+// @Summary List public holidays
+// @Tags Calendar
+// @Produce json
+// @Param year query int false "Year"
+// @Success 200 {object} models.CalendarHoliday
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/GetHolidays [get]
+func (repository *InitRepo) GetHolidays(c *gin.Context) {
+	var Parameter models.ParamGetHolidays
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.CalendarHoliday
+	query := repository.DbPg.Order("date")
+	if Parameter.Year > 0 {
+		query = query.Where("EXTRACT(YEAR FROM date) = ?", Parameter.Year)
+	}
+	if err := query.Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// InsertingHoliday godoc
+// @Summary Add or rename a public holiday
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param file body models.ParamInsertHoliday true "Holiday"
+// @Success 200 {object} models.CalendarHoliday
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/InsertingHoliday [post]
+func (repository *InitRepo) InsertingHoliday(c *gin.Context) {
+	var Parameter models.ParamInsertHoliday
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.ParseInLocation("2006-01-02", Parameter.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must use the format YYYY-MM-DD"})
+		return
+	}
+	holiday := []models.CalendarHoliday{{Date: date, Name: Parameter.Name, Source: models.HolidaySourceManual, CreatedBy: Parameter.Userid}}
+	if err := repository.upsertHolidays(holiday); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  holiday[0],
+	})
+}
+
+// ImportHolidayICal godoc
+// @Summary Import public holidays from an iCalendar file
+// @Tags Calendar
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "iCalendar (.ics) file"
+// @Param userid formData string false "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/ImportHolidayICal [post]
+func (repository *InitRepo) ImportHolidayICal(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the file"})
+		return
+	}
+	defer reader.Close()
+	events, err := helper.ParseICalHolidays(reader, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid iCalendar file: " + err.Error()})
+		return
+	}
+	holidays := make([]models.CalendarHoliday, 0, len(events))
+	for _, event := range events {
+		holidays = append(holidays, models.CalendarHoliday{
+			Date:      event.Date,
+			Name:      event.Name,
+			Source:    models.HolidaySourceICal,
+			CreatedBy: c.PostForm("userid"),
+		})
+	}
+	holidays = mergeHolidays(holidays)
+	if err := repository.upsertHolidays(holidays); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  fmt.Sprintf("%d holidays imported", len(holidays)),
+	})
+}
+
+// mergeHolidays folds holidays sharing a date into one, joining their names.
+func mergeHolidays(holidays []models.CalendarHoliday) []models.CalendarHoliday {
+	byDate := make(map[string]int, len(holidays))
+	merged := make([]models.CalendarHoliday, 0, len(holidays))
+	for _, holiday := range holidays {
+		key := holiday.Date.Format("2006-01-02")
+		i, seen := byDate[key]
+		if !seen {
+			byDate[key] = len(merged)
+			merged = append(merged, holiday)
+			continue
+		}
+		name := merged[i].Name + " / " + holiday.Name
+		if !strings.Contains(merged[i].Name, holiday.Name) && len(name) <= 200 {
+			merged[i].Name = name
+		}
+	}
+	return merged
+}
+
+// upsertHolidays inserts holidays, renaming the ones already on file.
+// Callers merge holidays sharing a date first, since one statement cannot
+// update the same row twice.
+func (repository *InitRepo) upsertHolidays(holidays []models.CalendarHoliday) error {
+	if len(holidays) == 0 {
+		return nil
+	}
+	return repository.DbPg.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "source", "created_by"}),
+	}).Create(&holidays).Error
+}
+
+// DeletingHoliday godoc
+// @Summary Delete a public holiday
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCalendarID true "Holiday ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/DeletingHoliday [post]
+func (repository *InitRepo) DeletingHoliday(c *gin.Context) {
+	var Parameter models.ParamCalendarID
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Delete(&models.CalendarHoliday{}, Parameter.ID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// GetDepartmentClosures godoc
+// @Summary List department closures
+// @Tags Calendar
+// @Produce json
+// @Param departemen query string false "Department"
+// @Success 200 {object} models.CalendarDepartmentClosure
+// @Router /Tasklist/GetDepartmentClosures [get]
+func (repository *InitRepo) GetDepartmentClosures(c *gin.Context) {
+	var Parameter models.ParamGetClosures
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.CalendarDepartmentClosure
+	query := repository.DbPg.Order("start_date")
+	if Parameter.Departemen != "" {
+		query = query.Where("departemen = ?", Parameter.Departemen)
+	}
+	if err := query.Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// InsertingDepartmentClosure godoc
+// @Summary Close a department for a date range
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param file body models.ParamInsertClosure true "Closure"
+// @Success 200 {object} models.CalendarDepartmentClosure
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/InsertingDepartmentClosure [post]
+func (repository *InitRepo) InsertingDepartmentClosure(c *gin.Context) {
+	var Parameter models.ParamInsertClosure
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start, errStart := time.ParseInLocation("2006-01-02", Parameter.Start_Date, time.Local)
+	end, errEnd := time.ParseInLocation("2006-01-02", Parameter.End_Date, time.Local)
+	if errStart != nil || errEnd != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date must be YYYY-MM-DD with start_date on or before end_date"})
+		return
+	}
+	closure := models.CalendarDepartmentClosure{
+		Departemen: Parameter.Departemen,
+		StartDate:  start,
+		EndDate:    end,
+		Reason:     Parameter.Reason,
+		CreatedBy:  Parameter.Userid,
+	}
+	if err := repository.DbPg.Create(&closure).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  closure,
+	})
+}
+
+// DeletingDepartmentClosure godoc
+// @Summary Delete a department closure
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCalendarID true "Closure ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/DeletingDepartmentClosure [post]
+func (repository *InitRepo) DeletingDepartmentClosure(c *gin.Context) {
+	var Parameter models.ParamCalendarID
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Delete(&models.CalendarDepartmentClosure{}, Parameter.ID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// CalculateWorkingDate godoc
+// @Summary Move a date by a number of working days
+// @Description Negative days count backwards. Also reports whether the given date is a working day.
+// @Tags Calendar
+// @Produce json
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param days query int false "Working days to add"
+// @Param departemen query string false "Department"
+// @Success 200 {object} models.ValueCalculateWorkingDate
+// @Router /Tasklist/CalculateWorkingDate [get]
+func (repository *InitRepo) CalculateWorkingDate(c *gin.Context) {
+	var Parameter models.ParamCalculateWorkingDate
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.ParseInLocation("2006-01-02", Parameter.Date, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must use the format YYYY-MM-DD"})
+		return
+	}
+	cal, err := repository.loadBusinessCalendar(Parameter.Departemen)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	Value := models.ValueCalculateWorkingDate{
+		Date:             Parameter.Date,
+		Days:             Parameter.Days,
+		Result_Date:      cal.AddWorkingDays(date, Parameter.Days).Format("2006-01-02"),
+		Is_Working_Day:   cal.IsWorkingDay(date),
+		Non_Working_Note: cal.NonWorkingReason(date),
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultCommentEditWindow = 15 * time.Minute
+
+// commentEditWindow is how long authors may edit or delete their own
+// comments, from COMMENT_EDIT_WINDOW_MINUTES.
+func commentEditWindow() time.Duration {
+	minutes, err := strconv.Atoi(helper.GodotEnv("COMMENT_EDIT_WINDOW_MINUTES"))
+	if err != nil || minutes < 0 {
+		return defaultCommentEditWindow
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// isTasklistAdmin reports whether empNo is listed in TASKLIST_ADMINS.
+func isTasklistAdmin(empNo string) bool {
+	for _, admin := range strings.Split(helper.GodotEnv("TASKLIST_ADMINS"), ",") {
+		if strings.TrimSpace(admin) == empNo && empNo != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// taskComments lists the comments of a task as stored, without edits.
+func (repository *InitRepo) taskComments(taskID string) ([]models.GetCommentList, error) {
+	var comments []models.GetCommentList
+	err := repository.DbPg.Raw(models.QueryGetListCommentsByTask, taskID).Scan(&comments).Error
+	return comments, err
+}
+
+// loadComment returns a comment as stored together with its current state,
+// which starts from the stored comment if it was never changed.
+func (repository *InitRepo) loadComment(commentID string) (models.GetCommentList, models.TaskCommentState, error) {
+	var state models.TaskCommentState
+	var taskIDs []string
+	if err := repository.DbPg.Raw(models.Query_GettingTaskID+"?", commentID).Scan(&taskIDs).Error; err != nil {
+		return models.GetCommentList{}, state, err
+	}
+	if len(taskIDs) == 0 {
+		return models.GetCommentList{}, state, fmt.Errorf("comment %s not found", commentID)
+	}
+	comments, err := repository.taskComments(taskIDs[0])
+	if err != nil {
+		return models.GetCommentList{}, state, err
+	}
+	for _, comment := range comments {
+		if comment.Comment_ID != commentID {
+			continue
+		}
+		err := repository.DbPg.Where("comment_id = ?", commentID).First(&state).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			state = models.TaskCommentState{
+				CommentID:   commentID,
+				TaskID:      taskIDs[0],
+				Comments:    comment.Comments,
+				ContentName: comment.Content_Name,
+				FileID:      comment.File_ID,
+			}
+		} else if err != nil {
+			return comment, state, err
+		}
+		if state.DeletedAt != nil {
+			return comment, state, fmt.Errorf("comment %s was deleted", commentID)
+		}
+		return comment, state, nil
+	}
+	return models.GetCommentList{}, state, fmt.Errorf("comment %s not found", commentID)
+}
+
+// canChangeComment lets admins change any comment and authors their own
+// within the edit window.
+func canChangeComment(comment models.GetCommentList, userid string, now time.Time) error {
+	if isTasklistAdmin(userid) {
+		return nil
+	}
+	if comment.Emp_ID != userid {
+		return fmt.Errorf("only the author can change comment %s", comment.Comment_ID)
+	}
+	posted, err := helper.ParseLocalTimestamp(comment.Comment_Date)
+	if err != nil {
+		return err
+	}
+	if now.Sub(posted) > commentEditWindow() {
+		return fmt.Errorf("comment %s can only be changed within %s of posting", comment.Comment_ID, commentEditWindow())
+	}
+	return nil
+}
+
+// applyCommentStates replaces edited comments with their latest version and
+// drops deleted ones.
+func (repository *InitRepo) applyCommentStates(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	var states []models.TaskCommentState
+	if err := repository.DbPg.Where("task_id = ? and comment_id in ?", taskID, commentIDs(comments)).Find(&states).Error; err != nil {
+		return comments, err
+	}
+	if len(states) == 0 {
+		return comments, nil
+	}
+	byComment := make(map[string]models.TaskCommentState, len(states))
+	for _, state := range states {
+		byComment[state.CommentID] = state
+	}
+	shown := make([]models.GetCommentList, 0, len(comments))
+	for _, comment := range comments {
+		state, ok := byComment[comment.Comment_ID]
+		if ok && state.DeletedAt != nil {
+			continue
+		}
+		if ok && state.EditedAt != nil {
+			comment.Comments = state.Comments
+			comment.Content_Name = state.ContentName
+			comment.File_ID = state.FileID
+			comment.Edited = true
+			comment.Edited_At = state.EditedAt
+			comment.Revision = state.Revision
+		}
+		shown = append(shown, comment)
+	}
+	return shown, nil
+}
+
+// EditingComment godoc
+// @Summary Edit a comment
+// @Description Authors may edit their own comments within COMMENT_EDIT_WINDOW_MINUTES of posting; admins may edit any comment. The replaced version is kept.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param file body models.ParamEditingComment true "Edited comment"
+// @Success 200 {object} models.TaskCommentState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /Tasklist/EditingComment [post]
+func (repository *InitRepo) EditingComment(c *gin.Context) {
+	var Parameter models.ParamEditingComment
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	comment, state, err := repository.loadComment(Parameter.Comment_ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	if err := canChangeComment(comment, Parameter.Userid, now); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous := models.TaskCommentRevision{
+		CommentID:   state.CommentID,
+		Revision:    state.Revision,
+		Comments:    state.Comments,
+		ContentName: state.ContentName,
+		FileID:      state.FileID,
+		WrittenBy:   comment.Emp_ID,
+	}
+	if state.EditedAt != nil {
+		previous.WrittenBy = state.EditedBy
+		previous.WrittenAt = *state.EditedAt
+	} else if posted, err := helper.ParseLocalTimestamp(comment.Comment_Date); err == nil {
+		previous.WrittenAt = posted
+	}
+	state.Comments = Parameter.Comments
+	if Parameter.Remove_Attachment {
+		state.ContentName = ""
+		state.FileID = ""
+	}
+	state.Revision++
+	state.EditedBy = Parameter.Userid
+	state.EditedAt = &now
+	state.UpdatedAt = now
+	err = repository.DbPg.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&previous).Error; err != nil {
+			return err
+		}
+		return tx.Save(&state).Error
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	repository.updateMentions(state, comment.Emp_ID)
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  state,
+	})
+}
+
+// updateMentions re-reads the mentions of an edited comment and notifies
+// the people it newly mentions.
+func (repository *InitRepo) updateMentions(state models.TaskCommentState, author string) {
+	var before []models.TaskCommentMention
+	if err := repository.DbPg.Where("comment_id = ?", state.CommentID).Find(&before).Error; err != nil {
+		log.Printf("Failed to load mentions of comment %s: %v", state.CommentID, err)
+		return
+	}
+	var known []string
+	for _, mention := range before {
+		known = append(known, mention.EmpNo)
+	}
+	mentions := repository.resolveMentions(state.Comments)
+	if err := repository.saveMentions(state.CommentID, state.TaskID, mentions); err != nil {
+		log.Printf("Failed to save mentions of comment %s: %v", state.CommentID, err)
+		return
+	}
+	for _, empNo := range mentionedOfficers(nil, mentions, author) {
+		if slices.Contains(known, empNo) {
+			continue
+		}
+		if err := repository.insertNotif(empNo, "TaskList_Comments", state.TaskID, state.Comments); err != nil {
+			log.Printf("Failed to notify %s of a mention: %v", empNo, err)
+		}
+	}
+}
+
+// DeletingComment godoc
+// @Summary Delete a comment
+// @Description Same rules as EditingComment. The comment is hidden from GetListtComments; its versions stay available from GetCommentRevisions.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param file body models.ParamDeletingComment true "Comment to delete"
+// @Success 200 {object} models.TaskCommentState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /Tasklist/DeletingComment [post]
+func (repository *InitRepo) DeletingComment(c *gin.Context) {
+	var Parameter models.ParamDeletingComment
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	comment, state, err := repository.loadComment(Parameter.Comment_ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	if err := canChangeComment(comment, Parameter.Userid, now); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	state.DeletedBy = Parameter.Userid
+	state.DeletedAt = &now
+	state.UpdatedAt = now
+	if err := repository.DbPg.Save(&state).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  state,
+	})
+}
+
+// GetCommentRevisions godoc
+// @Summary List earlier versions of a comment
+// @Tags Comments
+// @Produce json
+// @Param comment_id query string true "Comment ID"
+// @Success 200 {object} models.TaskCommentRevision
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/GetCommentRevisions [get]
+func (repository *InitRepo) GetCommentRevisions(c *gin.Context) {
+	var Parameter models.ParamCommentID
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.TaskCommentRevision
+	err := repository.DbPg.Where("comment_id = ?", Parameter.Comment_ID).Order("revision").Find(&Value).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range Value {
+		Value[i].CommentsHtml = helper.RenderMarkdown(Value[i].Comments)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxCommentFieldBytes = 1 << 20
+
+// storeCommentPart streams one uploaded file into the blob store.
+func (repository *InitRepo) storeCommentPart(ctx context.Context, part *multipart.Part) (models.TaskCommentAttachment, error) {
+	stored, err := repository.storeUploadedPart(ctx, part)
+	if err != nil {
+		return models.TaskCommentAttachment{}, err
+	}
+	return models.TaskCommentAttachment{
+		FileID:   stored.Key,
+		FileName: stored.Name,
+		Size:     stored.Size,
+		MimeType: stored.ContentType,
+	}, nil
+}
+
+// discardAttachments removes files stored for a comment that was not saved.
+func (repository *InitRepo) discardAttachments(attachments []models.TaskCommentAttachment) {
+	for _, attachment := range attachments {
+		if err := repository.Blobs.Delete(context.Background(), attachment.FileID); err != nil {
+			log.Printf("Failed to discard attachment %s: %v", attachment.FileID, err)
+		}
+	}
+}
+
+// attachAttachments fills Attachments on the comments of a task. A File_ID
+// of a comment posted through InsertingComment is listed as well.
+func (repository *InitRepo) attachAttachments(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	var attachments []models.TaskCommentAttachment
+	if err := repository.DbPg.Where("task_id = ? and comment_id in ?", taskID, commentIDs(comments)).Order("id").Find(&attachments).Error; err != nil {
+		return comments, err
+	}
+	byComment := make(map[string][]models.TaskCommentAttachment)
+	for _, attachment := range attachments {
+		byComment[attachment.CommentID] = append(byComment[attachment.CommentID], attachment)
+	}
+	for i := range comments {
+		comments[i].Attachments = []models.TaskCommentAttachment{}
+		if comments[i].File_ID != "" {
+			comments[i].Attachments = append(comments[i].Attachments, models.TaskCommentAttachment{
+				CommentID: comments[i].Comment_ID,
+				TaskID:    taskID,
+				FileID:    comments[i].File_ID,
+				FileName:  comments[i].Content_Name,
+			})
+		}
+		comments[i].Attachments = append(comments[i].Attachments, byComment[comments[i].Comment_ID]...)
+	}
+	return comments, nil
+}
+
+// InsertingCommentMultipart godoc
+// @Summary Post a comment with attachments
+// @Description Takes the comment fields and any number of files in one multipart form. Files are streamed to the blob store and stored one record each.
+// @Tags Comments
+// @Accept multipart/form-data
+// @Produce json
+// @Param Task_ID formData string true "Task ID"
+// @Param Comments formData string true "Comment"
+// @Param Emp_ID formData string true "Author"
+// @Param Parent_ID formData string false "Comment this one replies to"
+// @Param Tagging_User formData []string false "Employees to notify, may be repeated"
+// @Param files formData file false "Attachments, may be repeated"
+// @Success 200 {object} models.GetCommentList
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/InsertingCommentMultipart [post]
+func (repository *InitRepo) InsertingCommentMultipart(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var AddingValue models.InsertComments
+	var attachments []models.TaskCommentAttachment
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			repository.discardAttachments(attachments)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FileName() != "" {
+			attachment, err := repository.storeCommentPart(c.Request.Context(), part)
+			part.Close()
+			if err != nil {
+				repository.discardAttachments(attachments)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			attachments = append(attachments, attachment)
+			continue
+		}
+		value, err := io.ReadAll(io.LimitReader(part, maxCommentFieldBytes))
+		part.Close()
+		if err != nil {
+			repository.discardAttachments(attachments)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		switch part.FormName() {
+		case "Task_ID":
+			AddingValue.Task_ID = string(value)
+		case "Comments":
+			AddingValue.Comments = string(value)
+		case "Emp_ID":
+			AddingValue.Emp_ID = string(value)
+		case "Parent_ID":
+			AddingValue.Parent_ID = string(value)
+		case "Tagging_User":
+			AddingValue.Tagging_User = append(AddingValue.Tagging_User, string(value))
+		}
+	}
+	if AddingValue.Task_ID == "" || AddingValue.Comments == "" || AddingValue.Emp_ID == "" {
+		repository.discardAttachments(attachments)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Task_ID, Comments and Emp_ID are required"})
+		return
+	}
+	var threadRoot string
+	if AddingValue.Parent_ID != "" {
+		root, err := repository.threadRoot(AddingValue.Task_ID, AddingValue.Parent_ID)
+		if err != nil {
+			repository.discardAttachments(attachments)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		threadRoot = root
+	}
+	mentions := repository.resolveMentions(AddingValue.Comments)
+	AddingValue.Tagging_User = mentionedOfficers(AddingValue.Tagging_User, mentions, AddingValue.Emp_ID)
+
+	commentID, err := repository.insertComment(AddingValue, "", "")
+	if err != nil {
+		repository.discardAttachments(attachments)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	posted, err := repository.postedComment(AddingValue.Task_ID, commentID)
+	if err != nil {
+		repository.discardAttachments(attachments)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	for i := range attachments {
+		attachments[i].CommentID = posted.Comment_ID
+		attachments[i].TaskID = AddingValue.Task_ID
+		attachments[i].UploadedBy = AddingValue.Emp_ID
+		attachments[i].CreatedAt = now
+	}
+	if len(attachments) > 0 {
+		if err := repository.DbPg.Create(&attachments).Error; err != nil {
+			// The comment stays; its files have nothing pointing at them.
+			repository.discardAttachments(attachments)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	repository.filePostedComment(AddingValue, posted, threadRoot, mentions)
+	for _, empNo := range AddingValue.Tagging_User {
+		if err := repository.insertNotif(empNo, "TaskList_Comments", AddingValue.Task_ID, AddingValue.Comments); err != nil {
+			log.Printf("Failed to notify %s of comment %s: %v", empNo, posted.Comment_ID, err)
+		}
+	}
+
+	posted.Comments_Html = helper.RenderMarkdown(posted.Comments)
+	posted.Parent_ID = threadRoot
+	posted.Mentions = mentions
+	if posted.Mentions == nil {
+		posted.Mentions = []models.TaskCommentMention{}
+	}
+	posted.Reactions = []models.ValueCommentReaction{}
+	posted.Attachments = attachments
+	if posted.Attachments == nil {
+		posted.Attachments = []models.TaskCommentAttachment{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  posted,
+	})
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCommentPageSize = 50
+	maxCommentPageSize     = 200
+)
+
+// commentTimestamp formats at as the wall clock Get_List_Comments reports,
+// for comparing with "Comment_Date".
+func commentTimestamp(at time.Time) string {
+	return at.In(time.Local).Format("2006-01-02 15:04:05.999999")
+}
+
+// decorateComments applies edits and attaches threads, mentions, reactions
+// and attachments to comments read from Get_List_Comments.
+func (repository *InitRepo) decorateComments(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	comments, err := repository.applyCommentStates(taskID, comments)
+	if err == nil {
+		comments, err = repository.attachThreads(taskID, comments)
+	}
+	if err == nil {
+		comments, err = repository.attachMentions(taskID, comments)
+	}
+	if err == nil {
+		comments, err = repository.attachReactions(taskID, comments)
+	}
+	if err == nil {
+		comments, err = repository.attachAttachments(taskID, comments)
+	}
+	if err != nil {
+		return nil, err
+	}
+	renderComments(comments)
+	return comments, nil
+}
+
+// visibleComments reads the comments of a task that were not deleted and
+// match condition, in order, at most limit of them when limit is positive.
+func (repository *InitRepo) visibleComments(taskID, condition, order string, limit int, args ...interface{}) ([]models.GetCommentList, error) {
+	query := models.QueryGetVisibleComments
+	if condition != "" {
+		query += " and " + condition
+	}
+	query += " order by " + order
+	if limit > 0 {
+		query += " limit " + strconv.Itoa(limit)
+	}
+	var comments []models.GetCommentList
+	if err := repository.DbPg.Raw(query, append([]interface{}{taskID}, args...)...).Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+	return repository.decorateComments(taskID, comments)
+}
+
+// commentSyncPoint is the time of the latest change to the comments of a
+// task, as recorded by the database.
+func (repository *InitRepo) commentSyncPoint(taskID string) (time.Time, error) {
+	var found models.ValueCommentSyncPoint
+	if err := repository.DbPg.Raw(models.QueryGetCommentSyncPoint, taskID, taskID, taskID).Scan(&found).Error; err != nil {
+		return time.Time{}, err
+	}
+	return latestCommentChange(found), nil
+}
+
+// latestCommentChange is the latest of the post, edit and deletion times
+// in found, all read as the server's wall clock.
+func latestCommentChange(found models.ValueCommentSyncPoint) time.Time {
+	var latest time.Time
+	if found.Posted != nil {
+		latest, _ = helper.ParseLocalTimestamp(*found.Posted)
+	}
+	for _, at := range []*time.Time{found.Edited, found.Deleted} {
+		if at == nil {
+			continue
+		}
+		if local := helper.LocalWallClock(*at); local.After(latest) {
+			latest = local
+		}
+	}
+	return latest
+}
+
+// splitCommentChanges files the comments changed after since under Added
+// when they were posted after it and under Edited otherwise.
+func splitCommentChanges(changes *models.ValueCommentChanges, comments []models.GetCommentList, since time.Time) {
+	for _, comment := range comments {
+		if commentPostedAt(comment).After(since) {
+			changes.Added = append(changes.Added, comment)
+		} else {
+			changes.Edited = append(changes.Edited, comment)
+		}
+	}
+}
+
+// commentChanges reads the comments of a task added, edited and deleted
+// after since.
+func (repository *InitRepo) commentChanges(taskID string, since time.Time) (models.ValueCommentChanges, error) {
+	changes := models.ValueCommentChanges{
+		Added:   []models.GetCommentList{},
+		Edited:  []models.GetCommentList{},
+		Deleted: []string{},
+	}
+	comments, err := repository.visibleComments(taskID, `(t."Comment_Date" > ?::timestamp or s."edited_at" > ?)`,
+		`t."Comment_Date", t."Comment_ID" collate "C"`, 0, commentTimestamp(since), since.In(time.Local))
+	if err != nil {
+		return changes, err
+	}
+	splitCommentChanges(&changes, comments, since)
+	var deleted []models.TaskCommentState
+	err = repository.DbPg.Where("task_id = ? and deleted_at > ?", taskID, since.In(time.Local)).Order("deleted_at").Find(&deleted).Error
+	if err != nil {
+		return changes, err
+	}
+	for _, state := range deleted {
+		changes.Deleted = append(changes.Deleted, state.CommentID)
+	}
+	return changes, nil
+}
+
+// commentPage reads up to limit comments after the one at cursorAt with
+// cursorID, or from the start when cursorID is empty. With threaded it
+// reads up to limit threads, keyed by their first comment. It also returns
+// the cursor of the next page, which is empty on the last page.
+func (repository *InitRepo) commentPage(taskID string, threaded, newestFirst bool, cursorAt time.Time, cursorID string, limit int) (interface{}, string, error) {
+	comparison, direction := ">", "asc"
+	if newestFirst {
+		comparison, direction = "<", "desc"
+	}
+	order := `t."Comment_Date" ` + direction + `, t."Comment_ID" collate "C" ` + direction
+	var conditions []string
+	var args []interface{}
+	if cursorID != "" {
+		conditions = append(conditions, `(t."Comment_Date", t."Comment_ID" collate "C") `+comparison+` (?::timestamp, ? collate "C")`)
+		args = append(args, commentTimestamp(cursorAt), cursorID)
+	}
+	if threaded {
+		// A reply whose first comment was deleted starts a thread of its own.
+		conditions = append(conditions, `(p."parent_id" is null or ps."deleted_at" is not null)`)
+	}
+	comments, err := repository.visibleComments(taskID, strings.Join(conditions, " and "), order, limit+1, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		next = helper.EncodeCursor(commentPostedAt(last), last.Comment_ID)
+	}
+	if !threaded {
+		return comments, next, nil
+	}
+
+	roots := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		roots = append(roots, comment.Comment_ID)
+	}
+	replies, err := repository.visibleComments(taskID, `p."parent_id" in ? and ps."deleted_at" is null`,
+		`t."Comment_Date", t."Comment_ID" collate "C"`, 0, roots)
+	if err != nil {
+		return nil, "", err
+	}
+	return threadComments(append(comments, replies...)), next, nil
+}
+
+// respondComments writes one page of GetListtComments when limit or cursor
+// is given, and only what changed when since is given. Both are read from
+// the database a page at a time rather than from the whole history.
+func (repository *InitRepo) respondComments(c *gin.Context, Parameter models.ParamComments) {
+	var since, cursorAt time.Time
+	var cursorID string
+	var err error
+	if Parameter.Since != "" {
+		if since, _, err = helper.DecodeCursor(Parameter.Since); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since: " + err.Error()})
+			return
+		}
+	} else if Parameter.Cursor != "" {
+		if cursorAt, cursorID, err = helper.DecodeCursor(Parameter.Cursor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor: " + err.Error()})
+			return
+		}
+	}
+	if Parameter.Since == "" && Parameter.Order != "" && Parameter.Order != "oldest" && Parameter.Order != "newest" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("order must be oldest or newest, got %q", Parameter.Order)})
+		return
+	}
+
+	// The sync point is read first, so changes made while the page is read
+	// are returned again by the next poll rather than missed.
+	syncPoint, err := repository.commentSyncPoint(Parameter.Task_ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if Parameter.Since != "" {
+		changes, err := repository.commentChanges(Parameter.Task_ID, since)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if syncPoint.Before(since) {
+			syncPoint = since
+		}
+		changes.Sync_Cursor = helper.EncodeCursor(syncPoint, "")
+		c.JSON(http.StatusOK, gin.H{
+			"code":  200,
+			"error": false,
+			"data":  changes,
+		})
+		return
+	}
+
+	limit := Parameter.Limit
+	if limit <= 0 {
+		limit = defaultCommentPageSize
+	}
+	limit = min(limit, maxCommentPageSize)
+	page := models.ValueCommentPage{Sync_Cursor: helper.EncodeCursor(syncPoint, "")}
+	page.Comments, page.Next_Cursor, err = repository.commentPage(Parameter.Task_ID, Parameter.Threaded, Parameter.Order == "newest", cursorAt, cursorID, limit)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  page,
+	})
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// TestCommentPollingAcrossEdit polls the changes of a task the way a client
+// does, with the server outside UTC. Timestamps are given as the driver
+// returns them: the server's wall clock labelled UTC.
+func TestCommentPollingAcrossEdit(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := time.Local
+	time.Local = jakarta
+	t.Cleanup(func() { time.Local = saved })
+	scanned := func(hour, minute int) *time.Time {
+		at := time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC)
+		return &at
+	}
+	poll := func(syncPoint time.Time) time.Time {
+		since, _, err := helper.DecodeCursor(helper.EncodeCursor(syncPoint, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return since
+	}
+	posted := "2026-10-19T10:00:00Z"
+	first := models.GetCommentList{Comment_ID: "C1", Comment_Date: posted, Comments: "first"}
+
+	// The first page hands out the time of the only comment.
+	sync := latestCommentChange(models.ValueCommentSyncPoint{Posted: &posted})
+	if want := time.Date(2026, 10, 19, 10, 0, 0, 0, jakarta); !sync.Equal(want) {
+		t.Fatalf("sync point = %v, want %v", sync, want)
+	}
+
+	// C1 is edited at 10:05; the next poll returns it as edited.
+	since := poll(sync)
+	var changes models.ValueCommentChanges
+	splitCommentChanges(&changes, []models.GetCommentList{first}, since)
+	if len(changes.Added) != 0 || len(changes.Edited) != 1 {
+		t.Errorf("after the edit: added %d, edited %d, want 0 and 1", len(changes.Added), len(changes.Edited))
+	}
+	sync = latestCommentChange(models.ValueCommentSyncPoint{Posted: &posted, Edited: scanned(10, 5)})
+	if want := time.Date(2026, 10, 19, 10, 5, 0, 0, jakarta); !sync.Equal(want) {
+		t.Fatalf("sync point after the edit = %v, want %v", sync, want)
+	}
+
+	// The poll after that compares with the edit's own wall clock, so the
+	// edit is not returned again and a comment posted at 10:07 is new.
+	since = poll(sync)
+	if got := commentTimestamp(since); got != "2026-10-19 10:05:00" {
+		t.Errorf("since is sent as %q, want the edit's wall clock", got)
+	}
+	second := models.GetCommentList{Comment_ID: "C2", Comment_Date: "2026-10-19T10:07:00Z", Comments: "second"}
+	changes = models.ValueCommentChanges{}
+	splitCommentChanges(&changes, []models.GetCommentList{second}, since)
+	if len(changes.Added) != 1 || len(changes.Edited) != 0 {
+		t.Errorf("after the new comment: added %d, edited %d, want 1 and 0", len(changes.Added), len(changes.Edited))
+	}
+
+	// A deletion at 10:09 moves the sync point past both.
+	sync = latestCommentChange(models.ValueCommentSyncPoint{Posted: &second.Comment_Date, Edited: scanned(10, 5), Deleted: scanned(10, 9)})
+	if want := time.Date(2026, 10, 19, 10, 9, 0, 0, jakarta); !sync.Equal(want) {
+		t.Errorf("sync point after the deletion = %v, want %v", sync, want)
+	}
+}
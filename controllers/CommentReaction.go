@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"fmt"
+	"go-todolist/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reactionEmoji returns the emoji of a reaction in models.CommentReactions.
+func reactionEmoji(code string) (string, error) {
+	for _, reaction := range models.CommentReactions {
+		if reaction.Code == code {
+			return reaction.Emoji, nil
+		}
+	}
+	return "", fmt.Errorf("unknown reaction %s", code)
+}
+
+// attachReactions fills Reactions on the comments of a task, one entry per
+// reaction used, in the order of models.CommentReactions.
+func (repository *InitRepo) attachReactions(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	var reactions []models.TaskCommentReaction
+	if err := repository.DbPg.Where("task_id = ? and comment_id in ?", taskID, commentIDs(comments)).Order("created_at").Find(&reactions).Error; err != nil {
+		return comments, err
+	}
+	byComment := make(map[string]map[string][]models.ValueReactedBy)
+	for _, reaction := range reactions {
+		if byComment[reaction.CommentID] == nil {
+			byComment[reaction.CommentID] = make(map[string][]models.ValueReactedBy)
+		}
+		byComment[reaction.CommentID][reaction.Reaction] = append(byComment[reaction.CommentID][reaction.Reaction],
+			models.ValueReactedBy{Emp_No: reaction.EmpNo, Emp_Name: reaction.EmpName})
+	}
+	for i := range comments {
+		comments[i].Reactions = []models.ValueCommentReaction{}
+		for _, reaction := range models.CommentReactions {
+			reactedBy := byComment[comments[i].Comment_ID][reaction.Code]
+			if len(reactedBy) == 0 {
+				continue
+			}
+			comments[i].Reactions = append(comments[i].Reactions, models.ValueCommentReaction{
+				Reaction:   reaction.Code,
+				Emoji:      reaction.Emoji,
+				Count:      len(reactedBy),
+				Reacted_By: reactedBy,
+			})
+		}
+	}
+	return comments, nil
+}
+
+// GetCommentReactionSet godoc
+// @Summary List the reactions that can be added to comments
+// @Tags Comments
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/GetCommentReactionSet [get]
+func (repository *InitRepo) GetCommentReactionSet(c *gin.Context) {
+	Value := make([]gin.H, 0, len(models.CommentReactions))
+	for _, reaction := range models.CommentReactions {
+		Value = append(Value, gin.H{"reaction": reaction.Code, "emoji": reaction.Emoji})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// AddingCommentReaction godoc
+// @Summary React to a comment
+// @Description Adds one of the reactions of GetCommentReactionSet. Reactions do not send notifications or emails.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCommentReaction true "Reaction"
+// @Success 200 {object} models.TaskCommentReaction
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/AddingCommentReaction [post]
+func (repository *InitRepo) AddingCommentReaction(c *gin.Context) {
+	var Parameter models.ParamCommentReaction
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := reactionEmoji(Parameter.Reaction); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, state, err := repository.loadComment(Parameter.Comment_ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reaction := models.TaskCommentReaction{
+		CommentID: Parameter.Comment_ID,
+		EmpNo:     Parameter.Userid,
+		Reaction:  Parameter.Reaction,
+		TaskID:    state.TaskID,
+		EmpName:   repository.employeeName(Parameter.Userid),
+		CreatedAt: time.Now(),
+	}
+	if err := repository.DbPg.FirstOrCreate(&reaction, models.TaskCommentReaction{
+		CommentID: reaction.CommentID,
+		EmpNo:     reaction.EmpNo,
+		Reaction:  reaction.Reaction,
+	}).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  reaction,
+	})
+}
+
+// RemovingCommentReaction godoc
+// @Summary Remove a reaction from a comment
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCommentReaction true "Reaction"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/RemovingCommentReaction [post]
+func (repository *InitRepo) RemovingCommentReaction(c *gin.Context) {
+	var Parameter models.ParamCommentReaction
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := repository.DbPg.Where("comment_id = ? AND emp_no = ? AND reaction = ?", Parameter.Comment_ID, Parameter.Userid, Parameter.Reaction).
+		Delete(&models.TaskCommentReaction{}).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"sort"
+	"time"
+)
+
+// threadRoot validates that parentID is a comment on taskID and returns the
+// comment that started its thread.
+func (repository *InitRepo) threadRoot(taskID, parentID string) (string, error) {
+	_, state, err := repository.loadComment(parentID)
+	if err != nil {
+		return "", err
+	}
+	if state.TaskID != taskID {
+		return "", fmt.Errorf("comment %s does not belong to task %s", parentID, taskID)
+	}
+	var parent []models.TaskCommentThread
+	if err := repository.DbPg.Where("comment_id = ?", parentID).Find(&parent).Error; err != nil {
+		return "", err
+	}
+	if len(parent) > 0 {
+		return parent[0].ParentID, nil
+	}
+	return parentID, nil
+}
+
+// commentPostedAt parses the Comment_Date returned by Get_List_Comments.
+func commentPostedAt(comment models.GetCommentList) time.Time {
+	posted, _ := helper.ParseLocalTimestamp(comment.Comment_Date)
+	return posted
+}
+
+// insertComment posts a comment through SP_InsertingComments and returns
+// its ID.
+func (repository *InitRepo) insertComment(comment models.InsertComments, filePath, contentName string) (string, error) {
+	var commentID string
+	err := repository.DbPg.Raw(models.QueryInsertingCommentReturningID,
+		comment.Task_ID, comment.Comments, comment.Emp_ID, filePath, contentName, "").Scan(&commentID).Error
+	return commentID, err
+}
+
+// postedComment reads the comment commentID just posted on taskID.
+func (repository *InitRepo) postedComment(taskID, commentID string) (models.GetCommentList, error) {
+	comments, err := repository.taskComments(taskID)
+	if err != nil {
+		return models.GetCommentList{}, err
+	}
+	for _, comment := range comments {
+		if comment.Comment_ID == commentID {
+			return comment, nil
+		}
+	}
+	return models.GetCommentList{}, fmt.Errorf("new comment %s on task %s not found", commentID, taskID)
+}
+
+// recordReply files reply under rootID and notifies everyone who took part
+// in the thread, except the author and skip.
+func (repository *InitRepo) recordReply(comment models.InsertComments, reply models.GetCommentList, rootID string, skip []string) error {
+	err := repository.DbPg.Create(&models.TaskCommentThread{
+		CommentID: reply.Comment_ID,
+		TaskID:    comment.Task_ID,
+		ParentID:  rootID,
+		CreatedAt: time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	participants, err := repository.threadParticipants(comment.Task_ID, rootID)
+	if err != nil {
+		return err
+	}
+	notified := map[string]bool{comment.Emp_ID: true}
+	for _, empNo := range skip {
+		notified[empNo] = true
+	}
+	message := reply.Emp_NAME + " replied: " + comment.Comments
+	for _, empNo := range participants {
+		if notified[empNo] {
+			continue
+		}
+		notified[empNo] = true
+		if err := repository.insertNotif(empNo, "TaskList_Comments", comment.Task_ID, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// threadParticipants lists the authors of a thread, starting with the
+// author of its first comment.
+func (repository *InitRepo) threadParticipants(taskID, rootID string) ([]string, error) {
+	var replies []models.TaskCommentThread
+	if err := repository.DbPg.Where("parent_id = ?", rootID).Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	inThread := map[string]bool{rootID: true}
+	for _, reply := range replies {
+		inThread[reply.CommentID] = true
+	}
+	comments, err := repository.taskComments(taskID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(comments, func(i, j int) bool { return commentPostedAt(comments[i]).Before(commentPostedAt(comments[j])) })
+	var participants []string
+	for _, comment := range comments {
+		if inThread[comment.Comment_ID] {
+			participants = append(participants, comment.Emp_ID)
+		}
+	}
+	return participants, nil
+}
+
+// commentIDs lists the IDs of comments.
+func commentIDs(comments []models.GetCommentList) []string {
+	ids := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.Comment_ID)
+	}
+	return ids
+}
+
+// attachThreads fills Parent_ID and Reply_Count on the comments of a task.
+// Replies are counted in the database, as they need not be among comments.
+func (repository *InitRepo) attachThreads(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	ids := commentIDs(comments)
+	var links []models.TaskCommentThread
+	if err := repository.DbPg.Where("task_id = ? and comment_id in ?", taskID, ids).Find(&links).Error; err != nil {
+		return comments, err
+	}
+	parents := make(map[string]string, len(links))
+	for _, link := range links {
+		parents[link.CommentID] = link.ParentID
+	}
+	var counts []models.ValueReplyCount
+	err := repository.DbPg.Raw(models.QueryCountReplies, taskID, ids).Scan(&counts).Error
+	if err != nil {
+		return comments, err
+	}
+	replyCounts := make(map[string]int, len(counts))
+	for _, count := range counts {
+		replyCounts[count.Parent_ID] = count.Replies
+	}
+	for i := range comments {
+		comments[i].Parent_ID = parents[comments[i].Comment_ID]
+		comments[i].Reply_Count = replyCounts[comments[i].Comment_ID]
+	}
+	return comments, nil
+}
+
+// threadComments groups comments into threads in the order of their first
+// comment. Replies whose first comment was deleted are shown on their own.
+func threadComments(comments []models.GetCommentList) []models.ValueCommentThread {
+	shown := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		shown[comment.Comment_ID] = true
+	}
+	replies := make(map[string][]models.GetCommentList)
+	for _, comment := range comments {
+		if comment.Parent_ID != "" && shown[comment.Parent_ID] {
+			replies[comment.Parent_ID] = append(replies[comment.Parent_ID], comment)
+		}
+	}
+	threads := make([]models.ValueCommentThread, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Parent_ID != "" && shown[comment.Parent_ID] {
+			continue
+		}
+		thread := models.ValueCommentThread{GetCommentList: comment, Replies: replies[comment.Comment_ID]}
+		sort.SliceStable(thread.Replies, func(i, j int) bool {
+			return commentPostedAt(thread.Replies[i]).Before(commentPostedAt(thread.Replies[j]))
+		})
+		if thread.Replies == nil {
+			thread.Replies = []models.GetCommentList{}
+		}
+		threads = append(threads, thread)
+	}
+	return threads
+}
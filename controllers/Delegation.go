@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"go-todolist/models"
+	"log"
+	"time"
+)
+
+// activeDelegation returns the out-of-office period of an employee that
+// covers at and names a delegate, if any.
+func (repository *InitRepo) activeDelegation(empNo string, at time.Time) (*models.UserOutOfOffice, error) {
+	var periods []models.UserOutOfOffice
+	err := repository.DbPg.Where("emp_no = ? AND delegate_to <> '' AND start_date <= ? AND end_date >= ? AND returned_at IS NULL", empNo, at, at).
+		Order("end_date desc").Limit(1).Find(&periods).Error
+	if err != nil || len(periods) == 0 {
+		return nil, err
+	}
+	return &periods[0], nil
+}
+
+// recordDelegation marks a task, already assigned to the delegate, as held
+// for the owner of the period so it is handed back when the period ends.
+func (repository *InitRepo) recordDelegation(period models.UserOutOfOffice, taskID, assigner string) error {
+	if err := repository.assignTaskUser(taskID, "", period.DelegateTo, assigner); err != nil {
+		return err
+	}
+	return repository.DbPg.Create(&models.TaskDelegation{
+		OutOfOfficeID: period.ID,
+		TaskID:        taskID,
+		Owner:         period.EmpNo,
+		Delegate:      period.DelegateTo,
+		DelegatedAt:   time.Now(),
+	}).Error
+}
+
+// RunOutOfOffice hands open tasks over when an out-of-office period starts
+// and back when it ends, every interval until the process exits.
+func (repository *InitRepo) RunOutOfOffice(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := repository.processOutOfOffice(time.Now()); err != nil {
+			log.Printf("Out of office: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+func (repository *InitRepo) processOutOfOffice(now time.Time) error {
+	var starting []models.UserOutOfOffice
+	err := repository.DbPg.Where("hand_over_open_tasks AND delegate_to <> '' AND handed_over_at IS NULL AND start_date <= ? AND end_date >= ?", now, now).
+		Find(&starting).Error
+	if err != nil {
+		return err
+	}
+	for _, period := range starting {
+		if err := repository.handOverOpenTasks(period, now); err != nil {
+			return err
+		}
+	}
+
+	var ended []models.UserOutOfOffice
+	if err := repository.DbPg.Where("delegate_to <> '' AND returned_at IS NULL AND end_date < ?", now).Find(&ended).Error; err != nil {
+		return err
+	}
+	for _, period := range ended {
+		if err := repository.returnDelegatedTasks(period, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handOverOpenTasks moves every open task of the period's owner to the
+// delegate.
+func (repository *InitRepo) handOverOpenTasks(period models.UserOutOfOffice, now time.Time) error {
+	var tasks []models.WorkloadTask
+	if err := repository.DbPg.Raw(models.QueryGetOpenTaskByAssignee, period.EmpNo).Scan(&tasks).Error; err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := repository.recordDelegation(period, task.Task_ID, period.EmpNo); err != nil {
+			return err
+		}
+		message := "Delegated by " + repository.employeeName(period.EmpNo) + " until " + period.EndDate.Format("2006-01-02")
+		if err := repository.insertNotif(period.DelegateTo, "TaskList_Delegation", task.Task_ID, message); err != nil {
+			log.Printf("Out of office: failed to notify %s: %v", period.DelegateTo, err)
+		}
+	}
+	return repository.DbPg.Model(&period).Update("handed_over_at", now).Error
+}
+
+// returnDelegatedTasks gives the owner back the delegated tasks the delegate
+// still has open. Tasks finished in the meantime stay where they are.
+func (repository *InitRepo) returnDelegatedTasks(period models.UserOutOfOffice, now time.Time) error {
+	var delegations []models.TaskDelegation
+	if err := repository.DbPg.Where("out_of_office_id = ? AND returned_at IS NULL", period.ID).Find(&delegations).Error; err != nil {
+		return err
+	}
+	var open []models.WorkloadTask
+	if err := repository.DbPg.Raw(models.QueryGetOpenTaskByAssignee, period.DelegateTo).Scan(&open).Error; err != nil {
+		return err
+	}
+	stillOpen := make(map[string]bool, len(open))
+	for _, task := range open {
+		stillOpen[task.Task_ID] = true
+	}
+	for _, delegation := range delegations {
+		if stillOpen[delegation.TaskID] {
+			if err := repository.assignTaskUser(delegation.TaskID, "", delegation.Owner, delegation.Delegate); err != nil {
+				return err
+			}
+		}
+		if err := repository.DbPg.Model(&delegation).Update("returned_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return repository.DbPg.Model(&period).Update("returned_at", now).Error
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"errors"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPresignTTL = 15 * time.Minute
+	// maxPresignedUploadBytes is the largest object a single S3 PUT takes.
+	maxPresignedUploadBytes = 5 << 30
+)
+
+// presignTTL is how long presigned URLs stay valid, from
+// PRESIGN_TTL_MINUTES.
+func presignTTL() time.Duration {
+	minutes, err := strconv.Atoi(helper.GodotEnv("PRESIGN_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return defaultPresignTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// presigner returns the blob store as a BlobPresigner, or writes an error
+// when the configured backend cannot presign.
+func (repository *InitRepo) presigner(c *gin.Context) (helper.BlobPresigner, bool) {
+	presigner, ok := repository.Blobs.(helper.BlobPresigner)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "presigned URLs need the s3 blob store, not " + repository.Blobs.Kind()})
+	}
+	return presigner, ok
+}
+
+// PresigningDocumentUpload godoc
+// @Summary Get an upload URL for a task document
+// @Description Returns a short-lived presigned PUT URL so the client uploads the file straight to R2. Send the returned headers with the PUT, then call CompletingDocumentUpload with the ObjectKey.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param file body models.ParamPresigningUpload true "Task, document type and file name"
+// @Success 200 {object} models.ValuePresignedURL
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 501 {object} map[string]string "Blob store cannot presign"
+// @Router /Tasklist/PresigningDocumentUpload [post]
+func (repository *InitRepo) PresigningDocumentUpload(c *gin.Context) {
+	var Parameter models.ParamPresigningUpload
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	presigner, ok := repository.presigner(c)
+	if !ok {
+		return
+	}
+	contentType := Parameter.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(Parameter.DocumentName))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ttl := presignTTL()
+	now := time.Now()
+	pending := models.TaskDocumentPresign{
+		ObjectKey:    helper.NewBlobKey(),
+		TaskID:       Parameter.TaskID,
+		DocumentType: Parameter.DocumentType,
+		DocumentName: filepath.Base(Parameter.DocumentName),
+		ContentType:  contentType,
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}
+	url, err := presigner.PresignPut(pending.ObjectKey, contentType, ttl)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Create(&pending).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data": models.ValuePresignedURL{
+			ObjectKey: pending.ObjectKey,
+			Method:    http.MethodPut,
+			Url:       url,
+			Headers:   map[string]string{"Content-Type": contentType},
+			ExpiresAt: pending.ExpiresAt,
+		},
+	})
+}
+
+// CompletingDocumentUpload godoc
+// @Summary Record a document uploaded through a presigned URL
+// @Description Checks that the object was uploaded and records it as a document of the task it was presigned for, with insert_task_document_upload. Each upload is recorded once.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCompletingUpload true "Object key and document status"
+// @Success 200 {object} models.TaskDocumentPresign
+// @Failure 400 {object} map[string]string "Invalid input or object not uploaded"
+// @Failure 404 {object} map[string]string "Unknown object key"
+// @Failure 409 {object} map[string]string "Already recorded"
+// @Router /Tasklist/CompletingDocumentUpload [post]
+func (repository *InitRepo) CompletingDocumentUpload(c *gin.Context) {
+	var Parameter models.ParamCompletingUpload
+	var Try []models.CategoryList
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var pending []models.TaskDocumentPresign
+	if err := repository.DbPg.Where("object_key = ?", Parameter.ObjectKey).Find(&pending).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(pending) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no upload was presigned for " + Parameter.ObjectKey})
+		return
+	}
+	upload := pending[0]
+
+	blob, err := repository.Blobs.Open(c.Request.Context(), upload.ObjectKey)
+	if errors.Is(err, helper.ErrBlobNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": upload.ObjectKey + " has not been uploaded"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	stored := blob.Info()
+	blob.Close()
+	if stored.Size > maxPresignedUploadBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": upload.ObjectKey + " is larger than allowed"})
+		return
+	}
+
+	// Claim the upload first, so a repeated callback cannot record it twice.
+	now := time.Now()
+	claim := repository.DbPg.Model(&models.TaskDocumentPresign{}).
+		Where("object_key = ? AND completed_at IS NULL", upload.ObjectKey).
+		Updates(map[string]interface{}{"completed_at": now, "size": stored.Size})
+	if claim.Error != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": claim.Error.Error()})
+		return
+	}
+	if claim.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": upload.ObjectKey + " has already been recorded"})
+		return
+	}
+	createdDate := now.Format("2006-01-02")
+	err = repository.DbPg.Raw(models.Query_InsertingDocumentUpload+"(?, ?, ?, ?, ?, ?, ?)",
+		upload.DocumentType,
+		createdDate, // p_created_date
+		Parameter.Status,
+		upload.TaskID,
+		upload.DocumentName,
+		upload.ObjectKey,
+		createdDate, // p_detail_created_date
+	).Scan(&Try).Error
+	if err != nil {
+		repository.DbPg.Model(&models.TaskDocumentPresign{}).
+			Where("object_key = ?", upload.ObjectKey).
+			Update("completed_at", nil)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	upload.CompletedAt = &now
+	upload.Size = stored.Size
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  upload,
+	})
+}
+
+// PresigningDocumentDownload godoc
+// @Summary Get a download URL for a task document
+// @Description Returns a short-lived presigned GET URL for a document uploaded through PresigningDocumentUpload. The object must belong to the given task and document type.
+// @Tags Upload
+// @Produce json
+// @Param TaskID query string true "Task ID"
+// @Param DocumentType query string true "Document type"
+// @Param ObjectKey query string true "Object key"
+// @Success 200 {object} models.ValuePresignedURL
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "No such document"
+// @Failure 501 {object} map[string]string "Blob store cannot presign"
+// @Router /Tasklist/PresigningDocumentDownload [get]
+func (repository *InitRepo) PresigningDocumentDownload(c *gin.Context) {
+	var Parameter models.ParamPresigningDownload
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	presigner, ok := repository.presigner(c)
+	if !ok {
+		return
+	}
+	var documents []models.TaskDocumentPresign
+	err := repository.DbPg.
+		Where("object_key = ? AND task_id = ? AND document_type = ? AND completed_at IS NOT NULL",
+			Parameter.ObjectKey, Parameter.TaskID, Parameter.DocumentType).
+		Find(&documents).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(documents) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no " + Parameter.DocumentType + " document " + Parameter.ObjectKey + " on task " + Parameter.TaskID})
+		return
+	}
+
+	ttl := presignTTL()
+	url, err := presigner.PresignGet(documents[0].ObjectKey, documents[0].DocumentName, ttl)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data": models.ValuePresignedURL{
+			ObjectKey: documents[0].ObjectKey,
+			Method:    http.MethodGet,
+			Url:       url,
+			Headers:   map[string]string{},
+			ExpiresAt: time.Now().Add(ttl),
+		},
+	})
+}
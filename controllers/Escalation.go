@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RunEscalation escalates overdue tasks every interval until the process
+// exits.
+func (repository *InitRepo) RunEscalation(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := repository.escalateOverdueTasks(time.Now()); err != nil {
+			log.Printf("Escalation: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// escalateOverdueTasks walks the supervisor chain of overdue tasks according
+// to the escalation rules. Each task is escalated once per level.
+func (repository *InitRepo) escalateOverdueTasks(now time.Time) error {
+	var rules []models.EscalationRule
+	if err := repository.DbPg.Where("active").Order("level, id").Find(&rules).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	var tasks []models.OverdueTask
+	if err := repository.DbPg.Raw(models.QueryGetOverdueTask).Scan(&tasks).Error; err != nil {
+		return err
+	}
+
+	calendars := make(map[string]*helper.BusinessCalendar)
+	for _, task := range tasks {
+		cal, ok := calendars[task.Departemen]
+		if !ok {
+			var err error
+			if cal, err = repository.loadBusinessCalendar(task.Departemen); err != nil {
+				return err
+			}
+			calendars[task.Departemen] = cal
+		}
+		task.Estimated_Time_Done = helper.LocalWallClock(task.Estimated_Time_Done)
+		overdue := cal.WorkingDuration(task.Estimated_Time_Done, now).Hours()
+
+		var escalated []int
+		if err := repository.DbPg.Model(&models.EscalationEvent{}).Where("task_id = ?", task.Task_ID).Pluck("level", &escalated).Error; err != nil {
+			return err
+		}
+		for _, rule := range dueEscalationRules(rules, task, overdue) {
+			if containsLevel(escalated, rule.Level) {
+				continue
+			}
+			if err := repository.escalateTask(task, rule, overdue, now); err != nil {
+				// Keep going: one missing supervisor must not block other tasks.
+				log.Printf("Escalation: task %s level %d: %v", task.Task_ID, rule.Level, err)
+			}
+		}
+	}
+	return nil
+}
+
+// dueEscalationRules returns, per level, the most specific matching rule
+// whose threshold has been reached, lowest level first.
+func dueEscalationRules(rules []models.EscalationRule, task models.OverdueTask, overdueHours float64) []models.EscalationRule {
+	best := make(map[int]models.EscalationRule)
+	bestScore := make(map[int]int)
+	for _, rule := range rules {
+		if float64(rule.AfterHours) > overdueHours || rule.Level < 1 {
+			continue
+		}
+		score := matchScore(
+			[3]string{rule.Priority, rule.Category, rule.Departemen},
+			[3]string{task.Priority, task.Topic, task.Departemen},
+		)
+		if score < 0 {
+			continue
+		}
+		if current, ok := bestScore[rule.Level]; ok && score <= current {
+			continue
+		}
+		best[rule.Level], bestScore[rule.Level] = rule, score
+	}
+	due := make([]models.EscalationRule, 0, len(best))
+	for _, rule := range best {
+		due = append(due, rule)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Level < due[j].Level })
+	return due
+}
+
+func containsLevel(levels []int, level int) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func (repository *InitRepo) escalateTask(task models.OverdueTask, rule models.EscalationRule, overdueHours float64, now time.Time) error {
+	supervisor := models.ListDataValidateUserLevel{Direct_Spv_No: task.User_Assign_To}
+	for level := 0; level < rule.Level; level++ {
+		next, err := repository.directSupervisor(supervisor.Direct_Spv_No)
+		if err != nil {
+			return err
+		}
+		supervisor = next
+	}
+
+	event := models.EscalationEvent{
+		TaskID:          task.Task_ID,
+		Level:           rule.Level,
+		RuleID:          rule.ID,
+		Subject:         task.Subject,
+		AssignTo:        task.User_Assign_To,
+		EscalatedTo:     supervisor.Direct_Spv_No,
+		EscalatedToName: supervisor.Direct_Spv_Name,
+		OverdueHours:    overdueHours,
+		CreatedAt:       now,
+	}
+	if rule.RaisePriorityTo != "" && rule.RaisePriorityTo != task.Priority {
+		event.RaisedPriority = rule.RaisePriorityTo
+	}
+	// The event is keyed by task and level, so only the run that records it
+	// raises the priority and notifies.
+	created := false
+	err := repository.DbPg.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+		if event.RaisedPriority == "" {
+			return nil
+		}
+		return tx.Exec(models.Query_UpdateTaskPriority+"(?, ?)", task.Task_ID, event.RaisedPriority).Error
+	})
+	if err != nil || !created {
+		return err
+	}
+
+	message := fmt.Sprintf("Task %s (%s) assigned to %s is %.1f working hours overdue",
+		task.Task_ID, task.Subject, repository.employeeName(task.User_Assign_To), overdueHours)
+	if err := repository.insertNotif(supervisor.Direct_Spv_No, "TaskList_Escalation", task.Task_ID, message); err != nil {
+		log.Printf("Escalation: failed to notify %s: %v", supervisor.Direct_Spv_No, err)
+	}
+	emailTo, err := repository.employeeEmail(supervisor.Direct_Spv_No)
+	if err != nil {
+		return err
+	}
+	return helper.SendEmailTemplate(emailTo, "Notifications_Escalation.html",
+		supervisor.Direct_Spv_Name,
+		task.Subject,
+		repository.employeeName(task.User_Assign_To),
+		task.Estimated_Time_Done.Format("2006-01-02:15:04"),
+		fmt.Sprintf("%.1f", overdueHours),
+		helper.TaskLinkButton(task.Task_ID, "Show The Task Here"),
+		event.RaisedPriority,
+	)
+}
+
+// GetEscalationRules godoc
+// @Summary List escalation rules
+// @Tags Escalation
+// @Produce json
+// @Success 200 {object} models.EscalationRule
+// @Router /Tasklist/GetEscalationRules [get]
+func (repository *InitRepo) GetEscalationRules(c *gin.Context) {
+	var Value []models.EscalationRule
+	if err := repository.DbPg.Order("level, id").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// SavingEscalationRule godoc
+// @Summary Create or update an escalation rule
+// @Description Omit id to create a rule.
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param file body models.EscalationRule true "Escalation rule"
+// @Success 200 {object} models.EscalationRule
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/SavingEscalationRule [post]
+func (repository *InitRepo) SavingEscalationRule(c *gin.Context) {
+	var Parameter models.EscalationRule
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if Parameter.Level < 1 || Parameter.AfterHours < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level must be at least 1 and after_hours must not be negative"})
+		return
+	}
+	// An omitted flag defaults to true; false is kept as given.
+	if Parameter.Active == nil {
+		active := true
+		Parameter.Active = &active
+	}
+	if err := repository.DbPg.Save(&Parameter).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Parameter,
+	})
+}
+
+// DeletingEscalationRule godoc
+// @Summary Delete an escalation rule
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param file body models.ParamEscalationRuleID true "Escalation rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/DeletingEscalationRule [post]
+func (repository *InitRepo) DeletingEscalationRule(c *gin.Context) {
+	var Parameter models.ParamEscalationRuleID
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Delete(&models.EscalationRule{}, Parameter.ID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// GetEscalatedToMe godoc
+// @Summary List tasks escalated to a supervisor
+// @Tags Escalation
+// @Produce json
+// @Param userid query string true "Supervisor employee number"
+// @Success 200 {object} models.EscalationEvent
+// @Router /Tasklist/GetEscalatedToMe [get]
+func (repository *InitRepo) GetEscalatedToMe(c *gin.Context) {
+	var Parameter models.ParamEscalatedToMe
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.EscalationEvent
+	if err := repository.DbPg.Where("escalated_to = ?", Parameter.Userid).Order("created_at desc").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
@@ -0,0 +1,471 @@
+package controllers
+
+import (
+	"fmt"
+	"go-todolist/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// directoryUser looks an officer up in the user directory.
+func (repository *InitRepo) directoryUser(empNo string) (models.ValueGettingUserid, error) {
+	var found []models.ValueGettingUserid
+	if err := repository.DbMy.Raw(`Select number_officer,name from users where number_officer = ?`, empNo).Scan(&found).Error; err != nil {
+		return models.ValueGettingUserid{}, err
+	}
+	if len(found) == 0 {
+		return models.ValueGettingUserid{}, fmt.Errorf("%s is not in the user directory", empNo)
+	}
+	return found[0], nil
+}
+
+func (repository *InitRepo) isGroupMember(group, empNo string) (bool, error) {
+	members, err := repository.candidateAssignees("", group)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.Emp_No == empNo {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repository *InitRepo) recordGroupChange(group, empNo, empName, action, userid string) error {
+	return repository.DbPg.Create(&models.GroupMembershipHistory{
+		GroupName: group,
+		EmpNo:     empNo,
+		EmpName:   empName,
+		Action:    action,
+		ChangedBy: userid,
+		ChangedAt: time.Now(),
+	}).Error
+}
+
+func (repository *InitRepo) addGroupMember(group string, user models.ValueGettingUserid, userid string) error {
+	if err := repository.DbPg.Exec(models.QueryInsertGroupMember, user.Number_officer, user.Name, group).Error; err != nil {
+		return err
+	}
+	return repository.recordGroupChange(group, user.Number_officer, user.Name, models.GroupMembershipAdded, userid)
+}
+
+func (repository *InitRepo) removeGroupMember(group, empNo, userid string) error {
+	name := repository.employeeName(empNo)
+	if err := repository.DbPg.Exec(models.QueryDeleteGroupMember, empNo, group).Error; err != nil {
+		return err
+	}
+	return repository.recordGroupChange(group, empNo, name, models.GroupMembershipRemoved, userid)
+}
+
+// groupHasOpenTasks reports whether tasks assigned to the group are still
+// open, whoever holds them.
+func (repository *InitRepo) groupHasOpenTasks(group string) (bool, error) {
+	var open int64
+	err := repository.DbPg.Raw(models.QueryCountOpenGroupTasks, group).Scan(&open).Error
+	return open > 0, err
+}
+
+// loadGroup returns a group with its profile and members.
+func (repository *InitRepo) loadGroup(group string) (models.ValueGroup, error) {
+	value := models.ValueGroup{Group_Name: group}
+	var profile models.GroupProfile
+	if err := repository.DbPg.Where("group_name = ?", group).Limit(1).Find(&profile).Error; err != nil {
+		return value, err
+	}
+	value.Description = profile.Description
+	value.Leader_Emp_No = profile.LeaderEmpNo
+	members, err := repository.candidateAssignees("", group)
+	value.Members = members
+	return value, err
+}
+
+// GetGroups godoc
+// @Summary List groups with their leader and members
+// @Tags Group
+// @Produce json
+// @Success 200 {object} models.ValueGroup
+// @Router /Tasklist/GetGroups [get]
+func (repository *InitRepo) GetGroups(c *gin.Context) {
+	var names []string
+	if err := repository.DbPg.Raw(models.QueryGetGroupNames).Scan(&names).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Groups without members so far only exist as a profile.
+	var profiles []models.GroupProfile
+	if err := repository.DbPg.Order("group_name").Find(&profiles).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, profile := range profiles {
+		if !seen[profile.GroupName] {
+			names = append(names, profile.GroupName)
+		}
+	}
+
+	Value := make([]models.ValueGroup, 0, len(names))
+	for _, name := range names {
+		group, err := repository.loadGroup(name)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		Value = append(Value, group)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// GetGroup godoc
+// @Summary Get one group with its leader and members
+// @Tags Group
+// @Produce json
+// @Param group_name query string true "Group name"
+// @Success 200 {object} models.ValueGroup
+// @Router /Tasklist/GetGroup [get]
+func (repository *InitRepo) GetGroup(c *gin.Context) {
+	var Parameter models.ParamGroupName
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	Value, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// CreatingGroup godoc
+// @Summary Create a group
+// @Description group_name must contain GROUP so task assignment recognises it. Members and the leader must exist in the user directory.
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Param file body models.ParamCreatingGroup true "Group"
+// @Success 200 {object} models.ValueGroup
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/CreatingGroup [post]
+func (repository *InitRepo) CreatingGroup(c *gin.Context) {
+	var Parameter models.ParamCreatingGroup
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !strings.Contains(Parameter.Group_Name, "GROUP") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_name must contain GROUP"})
+		return
+	}
+	existing, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var profiles int64
+	if err := repository.DbPg.Model(&models.GroupProfile{}).Where("group_name = ?", Parameter.Group_Name).Count(&profiles).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(existing.Members) > 0 || profiles > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "group " + Parameter.Group_Name + " already exists"})
+		return
+	}
+
+	// Validate everyone before writing anything.
+	users := make([]models.ValueGettingUserid, 0, len(Parameter.Members))
+	listed := make(map[string]bool, len(Parameter.Members))
+	for _, empNo := range Parameter.Members {
+		if listed[empNo] {
+			continue
+		}
+		listed[empNo] = true
+		user, err := repository.directoryUser(empNo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		users = append(users, user)
+	}
+	if Parameter.Leader != "" && !listed[Parameter.Leader] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the leader must be one of the members"})
+		return
+	}
+
+	now := time.Now()
+	profile := models.GroupProfile{
+		GroupName:   Parameter.Group_Name,
+		Description: Parameter.Description,
+		LeaderEmpNo: Parameter.Leader,
+		CreatedBy:   Parameter.Userid,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := repository.DbPg.Create(&profile).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, user := range users {
+		if err := repository.addGroupMember(Parameter.Group_Name, user, Parameter.Userid); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if Parameter.Leader != "" {
+		if err := repository.recordGroupChange(Parameter.Group_Name, Parameter.Leader, repository.employeeName(Parameter.Leader), models.GroupLeaderChanged, Parameter.Userid); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	Value, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// UpdatingGroup godoc
+// @Summary Update a group's description or leader
+// @Description The leader must be a member of the group. An empty leader clears it.
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Param file body models.ParamUpdatingGroup true "Group"
+// @Success 200 {object} models.ValueGroup
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/UpdatingGroup [post]
+func (repository *InitRepo) UpdatingGroup(c *gin.Context) {
+	var Parameter models.ParamUpdatingGroup
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if Parameter.Leader != "" {
+		member, err := repository.isGroupMember(Parameter.Group_Name, Parameter.Leader)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !member {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the leader must be one of the members"})
+			return
+		}
+	}
+	var profile models.GroupProfile
+	if err := repository.DbPg.Where("group_name = ?", Parameter.Group_Name).Limit(1).Find(&profile).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if profile.GroupName == "" {
+		// Groups created directly in dynamic_group get their profile now.
+		profile = models.GroupProfile{GroupName: Parameter.Group_Name, CreatedBy: Parameter.Userid, CreatedAt: time.Now()}
+	}
+	leaderChanged := profile.LeaderEmpNo != Parameter.Leader
+	profile.Description = Parameter.Description
+	profile.LeaderEmpNo = Parameter.Leader
+	profile.UpdatedAt = time.Now()
+	if err := repository.DbPg.Save(&profile).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if leaderChanged {
+		if err := repository.recordGroupChange(Parameter.Group_Name, Parameter.Leader, repository.employeeName(Parameter.Leader), models.GroupLeaderChanged, Parameter.Userid); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	Value, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// DeletingGroup godoc
+// @Summary Delete a group
+// @Description Refused while the group still has open or unclaimed tasks.
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Param file body models.ParamGroupName true "Group"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /Tasklist/DeletingGroup [post]
+func (repository *InitRepo) DeletingGroup(c *gin.Context) {
+	var Parameter models.ParamGroupName
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	open, err := repository.groupHasOpenTasks(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if open {
+		c.JSON(http.StatusConflict, gin.H{"error": "group " + Parameter.Group_Name + " still has open tasks"})
+		return
+	}
+	members, err := repository.candidateAssignees("", Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, member := range members {
+		if err := repository.removeGroupMember(Parameter.Group_Name, member.Emp_No, Parameter.Userid); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := repository.DbPg.Where("group_name = ?", Parameter.Group_Name).Delete(&models.GroupProfile{}).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// AddingGroupMember godoc
+// @Summary Add a member to a group
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Param file body models.ParamGroupMember true "Member"
+// @Success 200 {object} models.ValueGroup
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/AddingGroupMember [post]
+func (repository *InitRepo) AddingGroupMember(c *gin.Context) {
+	var Parameter models.ParamGroupMember
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := repository.directoryUser(Parameter.Emp_No)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member, err := repository.isGroupMember(Parameter.Group_Name, Parameter.Emp_No)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if member {
+		c.JSON(http.StatusConflict, gin.H{"error": Parameter.Emp_No + " is already a member of " + Parameter.Group_Name})
+		return
+	}
+	if err := repository.addGroupMember(Parameter.Group_Name, user, Parameter.Userid); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	Value, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// RemovingGroupMember godoc
+// @Summary Remove a member from a group
+// @Description Removing the leader also clears the group's leader.
+// @Tags Group
+// @Accept json
+// @Produce json
+// @Param file body models.ParamGroupMember true "Member"
+// @Success 200 {object} models.ValueGroup
+// @Router /Tasklist/RemovingGroupMember [post]
+func (repository *InitRepo) RemovingGroupMember(c *gin.Context) {
+	var Parameter models.ParamGroupMember
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member, err := repository.isGroupMember(Parameter.Group_Name, Parameter.Emp_No)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !member {
+		c.JSON(http.StatusBadRequest, gin.H{"error": Parameter.Emp_No + " is not a member of " + Parameter.Group_Name})
+		return
+	}
+	if err := repository.removeGroupMember(Parameter.Group_Name, Parameter.Emp_No, Parameter.Userid); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = repository.DbPg.Model(&models.GroupProfile{}).
+		Where("group_name = ? AND leader_emp_no = ?", Parameter.Group_Name, Parameter.Emp_No).
+		Updates(map[string]interface{}{"leader_emp_no": "", "updated_at": time.Now()}).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	Value, err := repository.loadGroup(Parameter.Group_Name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// GetGroupMembershipHistory godoc
+// @Summary List the membership and leader changes of a group
+// @Tags Group
+// @Produce json
+// @Param group_name query string true "Group name"
+// @Success 200 {object} models.GroupMembershipHistory
+// @Router /Tasklist/GetGroupMembershipHistory [get]
+func (repository *InitRepo) GetGroupMembershipHistory(c *gin.Context) {
+	var Parameter models.ParamGroupName
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.GroupMembershipHistory
+	if err := repository.DbPg.Where("group_name = ?", Parameter.Group_Name).Order("changed_at desc, id desc").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
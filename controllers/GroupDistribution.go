@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"fmt"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// groupPolicy returns the distribution policy of a group, defaulting to
+// manual claim.
+func (repository *InitRepo) groupPolicy(group string) models.GroupDistributionPolicy {
+	var policy models.GroupDistributionPolicy
+	if err := repository.DbPg.Where("group_name = ?", group).First(&policy).Error; err != nil {
+		return models.GroupDistributionPolicy{GroupName: group, Strategy: models.GroupStrategyManualClaim}
+	}
+	return policy
+}
+
+// distributeGroupTask hands a newly created group task to a member
+// following the group's policy. It returns the chosen member, or an empty
+// string when the task waits to be claimed.
+func (repository *InitRepo) distributeGroupTask(taskID, group, assigner string) (string, error) {
+	policy := repository.groupPolicy(group)
+	assignment := models.GroupTaskAssignment{
+		TaskID:     taskID,
+		GroupName:  group,
+		Strategy:   policy.Strategy,
+		AssignedBy: assigner,
+		CreatedAt:  time.Now(),
+	}
+	if policy.Strategy == models.GroupStrategyManualClaim {
+		return "", repository.DbPg.Save(&assignment).Error
+	}
+
+	member, err := repository.pickGroupMember(policy)
+	if err != nil {
+		return "", err
+	}
+	if err := repository.assignTaskUser(taskID, group, member, assigner); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	assignment.EmpNo = member
+	assignment.AssignedAt = &now
+	if err := repository.DbPg.Save(&assignment).Error; err != nil {
+		return "", err
+	}
+	policy.LastAssignedTo = member
+	policy.UpdatedAt = now
+	if err := repository.DbPg.Save(&policy).Error; err != nil {
+		return "", err
+	}
+	return member, nil
+}
+
+// pickGroupMember chooses the next member for a round-robin or least-loaded
+// policy. Members who are out of office are skipped unless nobody else is
+// available.
+func (repository *InitRepo) pickGroupMember(policy models.GroupDistributionPolicy) (string, error) {
+	members, err := repository.candidateAssignees("", policy.GroupName)
+	if err != nil {
+		return "", err
+	}
+	if len(members) == 0 {
+		return "", fmt.Errorf("group %s has no members", policy.GroupName)
+	}
+
+	now := time.Now()
+	var suggestions []models.ValueAssigneeSuggestion
+	if policy.Strategy == models.GroupStrategyLeastLoaded {
+		if suggestions, err = repository.suggestAssignees(members, now); err != nil {
+			return "", err
+		}
+	} else {
+		empNos := make([]string, 0, len(members))
+		for _, member := range members {
+			empNos = append(empNos, member.Emp_No)
+		}
+		away, err := repository.outOfOfficeAt(empNos, now)
+		if err != nil {
+			return "", err
+		}
+		for _, member := range members {
+			_, isAway := away[member.Emp_No]
+			suggestions = append(suggestions, models.ValueAssigneeSuggestion{Emp_No: member.Emp_No, Available: !isAway})
+		}
+	}
+	anyAvailable := false
+	for _, suggestion := range suggestions {
+		anyAvailable = anyAvailable || suggestion.Available
+	}
+	eligible := func(s models.ValueAssigneeSuggestion) bool { return s.Available || !anyAvailable }
+
+	if policy.Strategy == models.GroupStrategyLeastLoaded {
+		best := -1
+		for i, suggestion := range suggestions {
+			if eligible(suggestion) && (best < 0 || suggestion.Score < suggestions[best].Score) {
+				best = i
+			}
+		}
+		return suggestions[best].Emp_No, nil
+	}
+
+	// Round robin continues after the member who received the last task.
+	start := 0
+	for i, suggestion := range suggestions {
+		if suggestion.Emp_No == policy.LastAssignedTo {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(suggestions); i++ {
+		suggestion := suggestions[(start+i)%len(suggestions)]
+		if eligible(suggestion) {
+			return suggestion.Emp_No, nil
+		}
+	}
+	return suggestions[start%len(suggestions)].Emp_No, nil
+}
+
+// assignTaskUser records the new assignee of a task through
+// user_assign_group_procedure so User_Assign_History follows the change.
+func (repository *InitRepo) assignTaskUser(taskID, group, member, assigner string) error {
+	return repository.DbPg.Exec(models.Query_InsertUpdategroupAssignTO+"(?, ?, ?, ?, ?)", taskID, member, group, assigner, models.AssignParamUpdate).Error
+}
+
+// distributeNewGroupTask distributes the task just created by a reporter,
+// notifies the chosen member and lets the rest of the group know about it.
+// Failures are logged so the task itself is kept.
+func (repository *InitRepo) distributeNewGroupTask(group, reporter, subject string) {
+	taskID, err := repository.latestTaskID(reporter)
+	if err != nil {
+		log.Printf("Group distribution: %v", err)
+		return
+	}
+	member, err := repository.distributeGroupTask(taskID, group, reporter)
+	if err != nil {
+		log.Printf("Group distribution: task %s: %v", taskID, err)
+	}
+	if member != "" {
+		if err := repository.notifyNewTask(taskID, subject, member, reporter); err != nil {
+			log.Printf("Group distribution: failed to notify %s: %v", member, err)
+		}
+	}
+	if err := repository.notifyGroupMembers(taskID, group, subject, reporter, member); err != nil {
+		log.Printf("Group notification: task %s: %v", taskID, err)
+	}
+}
+
+// GetGroupDistributionPolicies godoc
+// @Summary List group distribution policies
+// @Tags Group Distribution
+// @Produce json
+// @Success 200 {object} models.GroupDistributionPolicy
+// @Router /Tasklist/GetGroupDistributionPolicies [get]
+func (repository *InitRepo) GetGroupDistributionPolicies(c *gin.Context) {
+	var Value []models.GroupDistributionPolicy
+	if err := repository.DbPg.Order("group_name").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// SavingGroupDistributionPolicy godoc
+// @Summary Set how a group's tasks are distributed
+// @Description strategy is ROUND_ROBIN, LEAST_LOADED or MANUAL_CLAIM.
+// @Tags Group Distribution
+// @Accept json
+// @Produce json
+// @Param file body models.GroupDistributionPolicy true "Distribution policy"
+// @Success 200 {object} models.GroupDistributionPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/SavingGroupDistributionPolicy [post]
+func (repository *InitRepo) SavingGroupDistributionPolicy(c *gin.Context) {
+	var Parameter models.GroupDistributionPolicy
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch Parameter.Strategy {
+	case models.GroupStrategyRoundRobin, models.GroupStrategyLeastLoaded, models.GroupStrategyManualClaim:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy must be ROUND_ROBIN, LEAST_LOADED or MANUAL_CLAIM"})
+		return
+	}
+	// Keep the round-robin position when only the strategy changes.
+	Parameter.LastAssignedTo = repository.groupPolicy(Parameter.GroupName).LastAssignedTo
+	Parameter.UpdatedAt = time.Now()
+	if err := repository.DbPg.Save(&Parameter).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Parameter,
+	})
+}
+
+// GetUnclaimedGroupTasks godoc
+// @Summary List group tasks waiting to be claimed
+// @Tags Group Distribution
+// @Produce json
+// @Param group_name query string false "Group name"
+// @Success 200 {object} models.GroupTaskAssignment
+// @Router /Tasklist/GetUnclaimedGroupTasks [get]
+func (repository *InitRepo) GetUnclaimedGroupTasks(c *gin.Context) {
+	var Parameter models.ParamGroupFilter
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.GroupTaskAssignment
+	query := repository.DbPg.Where("emp_no = ''").Order("created_at")
+	if Parameter.Group_Name != "" {
+		query = query.Where("group_name = ?", Parameter.Group_Name)
+	}
+	if err := query.Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// ClaimingGroupTask godoc
+// @Summary Claim an unassigned group task
+// @Description Only members of the task's group can claim it, and only once.
+// @Tags Group Distribution
+// @Accept json
+// @Produce json
+// @Param file body models.ParamClaimGroupTask true "Claim"
+// @Success 200 {object} models.GroupTaskAssignment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /Tasklist/ClaimingGroupTask [post]
+func (repository *InitRepo) ClaimingGroupTask(c *gin.Context) {
+	var Parameter models.ParamClaimGroupTask
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var assignment models.GroupTaskAssignment
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Task_ID).First(&assignment).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task " + Parameter.Task_ID + " is not a group task"})
+		return
+	}
+	members, err := repository.candidateAssignees("", assignment.GroupName)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	isMember := false
+	for _, member := range members {
+		isMember = isMember || member.Emp_No == Parameter.Userid
+	}
+	if !isMember {
+		c.JSON(http.StatusBadRequest, gin.H{"error": Parameter.Userid + " is not a member of " + assignment.GroupName})
+		return
+	}
+
+	// The empty emp_no condition makes concurrent claims race safely.
+	now := time.Now()
+	result := repository.DbPg.Model(&models.GroupTaskAssignment{}).
+		Where("task_id = ? AND emp_no = ''", Parameter.Task_ID).
+		Updates(map[string]interface{}{"emp_no": Parameter.Userid, "assigned_at": now})
+	if result.Error != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "task " + Parameter.Task_ID + " has already been claimed"})
+		return
+	}
+	if err := repository.assignTaskUser(Parameter.Task_ID, assignment.GroupName, Parameter.Userid, Parameter.Userid); err != nil {
+		repository.DbPg.Model(&models.GroupTaskAssignment{}).Where("task_id = ?", Parameter.Task_ID).
+			Updates(map[string]interface{}{"emp_no": "", "assigned_at": nil})
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	assignment.EmpNo = Parameter.Userid
+	assignment.AssignedAt = &now
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  assignment,
+	})
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notifyGroupMembers sends every member of a group an in-app notification
+// for a new group task and queues it for their digest email, unless
+// GROUP_DIGEST=false turns the digest off. Members who opted out of the group, the reporter and
+// skip are left out.
+func (repository *InitRepo) notifyGroupMembers(taskID, group, subject, reporter, skip string) error {
+	members, err := repository.candidateAssignees("", group)
+	if err != nil {
+		return err
+	}
+	var optOuts []models.GroupNotificationOptOut
+	if err := repository.DbPg.Where("group_name = ?", group).Find(&optOuts).Error; err != nil {
+		return err
+	}
+	optedOut := make(map[string]bool, len(optOuts))
+	for _, optOut := range optOuts {
+		optedOut[optOut.EmpNo] = true
+	}
+
+	now := time.Now()
+	var entries []models.GroupDigestEntry
+	for _, member := range members {
+		if optedOut[member.Emp_No] || member.Emp_No == reporter || member.Emp_No == skip {
+			continue
+		}
+		message := fmt.Sprintf("New task for %s: %s", group, subject)
+		if err := repository.insertNotif(member.Emp_No, "TaskList_GroupTask", taskID, message); err != nil {
+			log.Printf("Group notification: failed to notify %s: %v", member.Emp_No, err)
+		}
+		entries = append(entries, models.GroupDigestEntry{
+			EmpNo:     member.Emp_No,
+			GroupName: group,
+			TaskID:    taskID,
+			Subject:   subject,
+			Reporter:  reporter,
+			CreatedAt: now,
+		})
+	}
+	// Nothing would ever send or clear the queue.
+	if len(entries) == 0 || helper.GodotEnv("GROUP_DIGEST") == "false" {
+		return nil
+	}
+	return repository.DbPg.Create(&entries).Error
+}
+
+// RunGroupDigest sends the queued group task digests every interval until
+// the process exits.
+func (repository *InitRepo) RunGroupDigest(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := repository.sendGroupDigests(time.Now()); err != nil {
+			log.Printf("Group digest: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// sendGroupDigests emails each member one list of the group tasks queued
+// for them since their last digest.
+func (repository *InitRepo) sendGroupDigests(now time.Time) error {
+	var entries []models.GroupDigestEntry
+	if err := repository.DbPg.Where("sent_at IS NULL").Order("emp_no, created_at").Find(&entries).Error; err != nil {
+		return err
+	}
+	byMember := make(map[string][]models.GroupDigestEntry)
+	var order []string
+	for _, entry := range entries {
+		if _, ok := byMember[entry.EmpNo]; !ok {
+			order = append(order, entry.EmpNo)
+		}
+		byMember[entry.EmpNo] = append(byMember[entry.EmpNo], entry)
+	}
+
+	for _, empNo := range order {
+		pending := byMember[empNo]
+		var items strings.Builder
+		ids := make([]int64, 0, len(pending))
+		for _, entry := range pending {
+			ids = append(ids, entry.ID)
+			fmt.Fprintf(&items, "<li>[%s] %s - %s</li>",
+				html.EscapeString(entry.GroupName),
+				html.EscapeString(entry.Subject),
+				helper.TaskLinkButton(entry.TaskID, entry.TaskID))
+		}
+		emailTo, err := repository.employeeEmail(empNo)
+		if err == nil {
+			err = helper.SendEmailTemplate(emailTo, "Notifications_Group_Digest.html",
+				repository.employeeName(empNo),
+				now.Format("2006-01-02:15:04"),
+				strconv.Itoa(len(pending)),
+				"<ul>"+items.String()+"</ul>",
+			)
+		}
+		if err != nil {
+			// Leave the entries queued for the next run.
+			log.Printf("Group digest: failed to send to %s: %v", empNo, err)
+			continue
+		}
+		if err := repository.DbPg.Model(&models.GroupDigestEntry{}).Where("id IN ?", ids).Update("sent_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGroupSubscriptions godoc
+// @Summary List a user's groups and whether they get their notifications
+// @Tags Group Notification
+// @Produce json
+// @Param userid query string true "Employee number"
+// @Success 200 {object} models.ValueGroupSubscription
+// @Router /Tasklist/GetGroupSubscriptions [get]
+func (repository *InitRepo) GetGroupSubscriptions(c *gin.Context) {
+	var Parameter models.ParamGroupSubscription
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var groups []string
+	if err := repository.DbPg.Raw(models.QueryGetGroupsOfMember, Parameter.Userid).Scan(&groups).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var optOuts []models.GroupNotificationOptOut
+	if err := repository.DbPg.Where("emp_no = ?", Parameter.Userid).Find(&optOuts).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	optedOut := make(map[string]bool, len(optOuts))
+	for _, optOut := range optOuts {
+		optedOut[optOut.GroupName] = true
+	}
+	Value := make([]models.ValueGroupSubscription, 0, len(groups))
+	for _, group := range groups {
+		Value = append(Value, models.ValueGroupSubscription{Group_Name: group, Subscribed: !optedOut[group]})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// UnsubscribingGroupNotification godoc
+// @Summary Stop notifications for one group's tasks
+// @Tags Group Notification
+// @Accept json
+// @Produce json
+// @Param file body models.ParamGroupOptOut true "Group"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/UnsubscribingGroupNotification [post]
+func (repository *InitRepo) UnsubscribingGroupNotification(c *gin.Context) {
+	var Parameter models.ParamGroupOptOut
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	optOut := models.GroupNotificationOptOut{EmpNo: Parameter.Userid, GroupName: Parameter.Group_Name, CreatedAt: time.Now()}
+	if err := repository.DbPg.Save(&optOut).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Drop what is still queued for the group so the next digest honours it.
+	err := repository.DbPg.Where("emp_no = ? AND group_name = ? AND sent_at IS NULL", Parameter.Userid, Parameter.Group_Name).
+		Delete(&models.GroupDigestEntry{}).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// SubscribingGroupNotification godoc
+// @Summary Resume notifications for one group's tasks
+// @Tags Group Notification
+// @Accept json
+// @Produce json
+// @Param file body models.ParamGroupOptOut true "Group"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/SubscribingGroupNotification [post]
+func (repository *InitRepo) SubscribingGroupNotification(c *gin.Context) {
+	var Parameter models.ParamGroupOptOut
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := repository.DbPg.Where("emp_no = ? AND group_name = ?", Parameter.Userid, Parameter.Group_Name).
+		Delete(&models.GroupNotificationOptOut{}).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"go-todolist/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const healthPingTimeout = 2 * time.Second
+
+// pingDatabase times a ping of db.
+func pingDatabase(ctx context.Context, db *gorm.DB) models.ValueDatabaseHealth {
+	var health models.ValueDatabaseHealth
+	if db == nil {
+		health.Error = "not connected"
+		return health
+	}
+	start := time.Now()
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	health.Latency_Ms = time.Since(start).Milliseconds()
+	health.Up = err == nil
+	if err != nil {
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// mongoHealth pings the shared MongoDB client and reads its pool counts.
+func (repository *InitRepo) mongoHealth(ctx context.Context) models.ValueMongoHealth {
+	var health models.ValueMongoHealth
+	if repository.Mongo == nil {
+		health.Error = "not connected"
+		return health
+	}
+	start := time.Now()
+	err := repository.Mongo.Ping(ctx, nil)
+	health.Latency_Ms = time.Since(start).Milliseconds()
+	health.Up = err == nil
+	if err != nil {
+		health.Error = err.Error()
+	}
+	pool := repository.MongoStats.Snapshot()
+	health.Open_Connections = pool.Open
+	health.In_Use_Connections = pool.InUse
+	health.Created_Connections = pool.Created
+	health.Closed_Connections = pool.Closed
+	health.Checkout_Failures = pool.CheckoutFailed
+	health.Sessions_In_Progress = repository.Mongo.NumberSessionsInProgress()
+	return health
+}
+
+// Health godoc
+// @Summary Service health
+// @Description Pings PostgreSQL, MySQL and MongoDB and reports the connection pool of the shared MongoDB client. Answers 503 when PostgreSQL, or MongoDB while it holds the files, is down.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} models.ValueHealth
+// @Failure 503 {object} models.ValueHealth
+// @Router /health [get]
+func (repository *InitRepo) Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthPingTimeout)
+	defer cancel()
+
+	Value := models.ValueHealth{
+		Status:     "ok",
+		Postgres:   pingDatabase(ctx, repository.DbPg),
+		MySQL:      pingDatabase(ctx, repository.DbMy),
+		Mongo:      repository.mongoHealth(ctx),
+		Blob_Store: repository.Blobs.Kind(),
+	}
+	status := http.StatusOK
+	if !Value.Postgres.Up || (!Value.Mongo.Up && Value.Blob_Store == "gridfs") {
+		Value.Status = "down"
+		status = http.StatusServiceUnavailable
+	} else if !Value.MySQL.Up || !Value.Mongo.Up {
+		Value.Status = "degraded"
+	}
+	c.JSON(status, gin.H{
+		"code":  status,
+		"error": status != http.StatusOK,
+		"data":  Value,
+	})
+}
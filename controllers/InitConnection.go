@@ -3,8 +3,12 @@ package controllers
 import (
 	"fmt"
 	"go-todolist/configs"
+	helper "go-todolist/helpers"
 	"go-todolist/models"
+	"log"
 
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"gorm.io/gorm"
 )
 
@@ -13,6 +17,13 @@ type Controller struct{}
 type InitRepo struct {
 	DbPg *gorm.DB // For PostgreSQL
 	DbMy *gorm.DB // For MySQL
+
+	Mongo           *mongo.Client           // Shared by every MongoDB operation
+	MongoStats      *configs.MongoPoolStats // Connection counts of Mongo
+	StoreDoc        *gridfs.Bucket          // Uploaded files
+	StoreDocTesting *gridfs.Bucket          // Files of InsertPDFToMongoDB_V1
+
+	Blobs helper.BlobStore // Uploaded files, on the backend named by BLOB_STORE
 }
 
 // NewConnection initializes the database connections and returns an InitRepo instance
@@ -29,12 +40,48 @@ func NewConnection() *InitRepo {
 	// Auto-migrate models for both databases
 	dbPg.AutoMigrate(&models.DeptList{}) // For PostgreSQL
 	dbMy.AutoMigrate(&models.DeptList{}) // For MySQL
+	dbPg.AutoMigrate(&models.SchedulerTaskState{}, &models.SchedulerRunLog{})
+	dbPg.AutoMigrate(&models.CalendarWorkingPattern{}, &models.CalendarHoliday{}, &models.CalendarDepartmentClosure{})
+	dbPg.AutoMigrate(&models.SlaPolicy{}, &models.TaskSlaState{}, &models.SlaBreachEvent{})
+	dbPg.AutoMigrate(&models.EscalationRule{}, &models.EscalationEvent{})
+	dbPg.AutoMigrate(&models.TaskEffortLog{}, &models.UserOutOfOffice{})
+	dbPg.AutoMigrate(&models.GroupDistributionPolicy{}, &models.GroupTaskAssignment{})
+	dbPg.AutoMigrate(&models.GroupNotificationOptOut{}, &models.GroupDigestEntry{})
+	dbPg.AutoMigrate(&models.TaskDelegation{})
+	dbPg.AutoMigrate(&models.ReassignmentRequest{})
+	dbPg.AutoMigrate(&models.TaskAssignee{}, &models.TaskCompletionRule{})
+	dbPg.AutoMigrate(&models.GroupProfile{}, &models.GroupMembershipHistory{})
+	dbPg.AutoMigrate(&models.TaskCommentState{}, &models.TaskCommentRevision{})
+	dbPg.AutoMigrate(&models.TaskCommentThread{})
+	dbPg.AutoMigrate(&models.TaskCommentMention{})
+	dbPg.AutoMigrate(&models.TaskCommentReaction{})
+	dbPg.AutoMigrate(&models.TaskCommentAttachment{})
+	dbPg.AutoMigrate(&models.TaskDocumentPresign{})
 
-	// Return the InitRepo with both database connections
-	return &InitRepo{
+	repository := &InitRepo{
 		DbPg: dbPg,
 		DbMy: dbMy,
 	}
+	// One MongoDB client and its buckets serve every request
+	mongoClient, mongoStats, err := configs.InitMongo()
+	if err == nil {
+		repository.Mongo, repository.MongoStats = mongoClient, mongoStats
+		repository.StoreDoc, err = configs.InitGridFSBucket(mongoClient, "StoreDoc")
+	}
+	if err == nil {
+		repository.StoreDocTesting, err = configs.InitGridFSBucket(mongoClient, "StoreDocTesting")
+	}
+	if err != nil {
+		log.Printf("MongoDB unavailable: %v", err)
+	}
+
+	repository.Blobs, err = helper.NewBlobStore(helper.GodotEnv("BLOB_STORE"), repository.StoreDoc)
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+
+	// Return the InitRepo with all connections
+	return repository
 }
 
 // NewController creates a new controller instance
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+)
+
+// renderTaskDescriptions fills Task_Desc_Html on task detail rows.
+func renderTaskDescriptions(Output interface{}) {
+	if rows, ok := Output.([]models.ListDataDetail); ok {
+		for i := range rows {
+			rows[i].Task_Desc_Html = helper.RenderMarkdown(rows[i].Task_Desc)
+		}
+	}
+}
+
+// renderComments fills Comments_Html on comments.
+func renderComments(comments []models.GetCommentList) {
+	for i := range comments {
+		comments[i].Comments_Html = helper.RenderMarkdown(comments[i].Comments)
+	}
+}
+
+// taskDescription returns the Markdown description of a task, or an empty
+// string when it cannot be read.
+func (repository *InitRepo) taskDescription(taskID string) string {
+	var found []models.ListDataDetail
+	err := repository.DbPg.Raw(`select "task_id","task_desc" from public."task_detail" where "task_id" = ? limit 1`, taskID).Scan(&found).Error
+	if err != nil || len(found) == 0 {
+		return ""
+	}
+	return found[0].Task_Desc
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCommentMentions caps the "@"s of one comment looked up in the user
+// directory.
+const maxCommentMentions = 20
+
+// resolveMentions finds the "@employee-number" and "@Display Name"
+// mentions of text in the user directory, with one query for the numbers
+// and one for the names. Names shared by several users are left
+// unresolved, as are the "@"s past the first maxCommentMentions.
+func (repository *InitRepo) resolveMentions(text string) []models.TaskCommentMention {
+	candidates := helper.FindMentionCandidates(text)
+	if len(candidates) > maxCommentMentions {
+		candidates = candidates[:maxCommentMentions]
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	tokens := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		tokens = append(tokens, candidate.Token)
+	}
+	var byNumber []models.ValueGettingUserid
+	if err := repository.DbMy.Raw(models.QueryFindUsersByNumber, tokens).Scan(&byNumber).Error; err != nil {
+		log.Printf("Failed to look up mentions: %v", err)
+		return nil
+	}
+	numbered := make(map[string]models.ValueGettingUserid, len(byNumber))
+	for _, user := range byNumber {
+		numbered[user.Number_officer] = user
+	}
+
+	var conditions []string
+	var prefixes []interface{}
+	for _, candidate := range candidates {
+		if _, ok := numbered[candidate.Token]; !ok {
+			conditions = append(conditions, "name like ?")
+			prefixes = append(prefixes, candidate.Token+"%")
+		}
+	}
+	var byName []models.ValueGettingUserid
+	if len(conditions) > 0 {
+		query := models.QueryFindUsers + strings.Join(conditions, " or ")
+		if err := repository.DbMy.Raw(query, prefixes...).Scan(&byName).Error; err != nil {
+			log.Printf("Failed to look up mentions: %v", err)
+		}
+	}
+	names := make([]string, 0, len(byName))
+	for _, user := range byName {
+		names = append(names, user.Name)
+	}
+
+	var mentions []models.TaskCommentMention
+	for _, candidate := range candidates {
+		if user, ok := numbered[candidate.Token]; ok {
+			mentions = append(mentions, models.TaskCommentMention{
+				Start:   candidate.Start,
+				Length:  1 + utf8.RuneCountInString(candidate.Token),
+				EmpNo:   user.Number_officer,
+				EmpName: user.Name,
+				Text:    "@" + candidate.Token,
+			})
+			continue
+		}
+		name, length := helper.MatchMentionName(candidate.Rest, names)
+		if length == 0 {
+			continue
+		}
+		var matched []models.ValueGettingUserid
+		for _, user := range byName {
+			if user.Name == name {
+				matched = append(matched, user)
+			}
+		}
+		if len(matched) != 1 {
+			continue
+		}
+		mentions = append(mentions, models.TaskCommentMention{
+			Start:   candidate.Start,
+			Length:  1 + length,
+			EmpNo:   matched[0].Number_officer,
+			EmpName: matched[0].Name,
+			Text:    "@" + string([]rune(candidate.Rest)[:length]),
+		})
+	}
+	return mentions
+}
+
+// mentionedOfficers adds the mentioned employees to tagged, skipping the
+// author and anyone already tagged.
+func mentionedOfficers(tagged []string, mentions []models.TaskCommentMention, author string) []string {
+	seen := map[string]bool{author: true}
+	for _, empNo := range tagged {
+		seen[empNo] = true
+	}
+	for _, mention := range mentions {
+		if !seen[mention.EmpNo] {
+			seen[mention.EmpNo] = true
+			tagged = append(tagged, mention.EmpNo)
+		}
+	}
+	return tagged
+}
+
+// saveMentions replaces the stored mentions of a comment.
+func (repository *InitRepo) saveMentions(commentID, taskID string, mentions []models.TaskCommentMention) error {
+	if err := repository.DbPg.Where("comment_id = ?", commentID).Delete(&models.TaskCommentMention{}).Error; err != nil {
+		return err
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+	for i := range mentions {
+		mentions[i].CommentID = commentID
+		mentions[i].TaskID = taskID
+	}
+	return repository.DbPg.Create(&mentions).Error
+}
+
+// attachMentions fills Mentions on the comments of a task.
+func (repository *InitRepo) attachMentions(taskID string, comments []models.GetCommentList) ([]models.GetCommentList, error) {
+	var mentions []models.TaskCommentMention
+	if err := repository.DbPg.Where("task_id = ? and comment_id in ?", taskID, commentIDs(comments)).Order("start").Find(&mentions).Error; err != nil {
+		return comments, err
+	}
+	byComment := make(map[string][]models.TaskCommentMention)
+	for _, mention := range mentions {
+		byComment[mention.CommentID] = append(byComment[mention.CommentID], mention)
+	}
+	for i := range comments {
+		comments[i].Mentions = byComment[comments[i].Comment_ID]
+		if comments[i].Mentions == nil {
+			comments[i].Mentions = []models.TaskCommentMention{}
+		}
+	}
+	return comments, nil
+}
+
+// recordPostedComment reads the comment just inserted and files its thread
+// and mentions.
+func (repository *InitRepo) recordPostedComment(comment models.InsertComments, commentID, threadRoot string, mentions []models.TaskCommentMention) {
+	if threadRoot == "" && len(mentions) == 0 {
+		return
+	}
+	posted, err := repository.postedComment(comment.Task_ID, commentID)
+	if err != nil {
+		log.Printf("Failed to find new comment on task %s: %v", comment.Task_ID, err)
+		return
+	}
+	repository.filePostedComment(comment, posted, threadRoot, mentions)
+}
+
+// filePostedComment files the thread and mentions of posted.
+func (repository *InitRepo) filePostedComment(comment models.InsertComments, posted models.GetCommentList, threadRoot string, mentions []models.TaskCommentMention) {
+	if threadRoot != "" {
+		if err := repository.recordReply(comment, posted, threadRoot, comment.Tagging_User); err != nil {
+			log.Printf("Failed to record reply to comment %s: %v", threadRoot, err)
+		}
+	}
+	if err := repository.saveMentions(posted.Comment_ID, comment.Task_ID, mentions); err != nil {
+		log.Printf("Failed to save mentions of comment %s: %v", posted.Comment_ID, err)
+	}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"time"
+)
+
+// insertNotif adds an in-app notification through SP_InsertNotif. While the
+// officer is out of office with a delegate, the delegate receives it.
+func (repository *InitRepo) insertNotif(officer, category, value, message string) error {
+	if period, err := repository.activeDelegation(officer, time.Now()); err == nil && period != nil {
+		message = "On behalf of " + repository.employeeName(officer) + ": " + message
+		officer = period.DelegateTo
+	}
+	return repository.DbPg.Exec(models.Query_InsertingNotif+"(?, ?, ?, ?)", officer, category, value, message).Error
+}
+
+// employeeName looks the employee up in dynamic_group, returning the number
+// itself when the employee is unknown.
+func (repository *InitRepo) employeeName(empNo string) string {
+	var found []models.FetchUsernameAssign
+	err := repository.DbPg.Raw(`select "emp_no","emp_name" from public."dynamic_group" where "emp_no" = ? limit 1`, empNo).Scan(&found).Error
+	if err != nil || len(found) == 0 {
+		return empNo
+	}
+	return found[0].Emp_Name
+}
+
+// employeeEmail returns the email address of an officer from the user directory.
+func (repository *InitRepo) employeeEmail(empNo string) (string, error) {
+	var found []models.Mailto
+	if err := repository.DbMy.Raw(`Select Email from users where number_officer = ?`, empNo).Scan(&found).Error; err != nil {
+		return "", err
+	}
+	if len(found) == 0 || found[0].Email == "" {
+		return "", fmt.Errorf("no email address for %s", empNo)
+	}
+	return found[0].Email, nil
+}
+
+// directSupervisor returns the direct supervisor of an employee as reported
+// by ValidateUserLevel.
+func (repository *InitRepo) directSupervisor(empNo string) (models.ListDataValidateUserLevel, error) {
+	var found []models.ListDataValidateUserLevel
+	query := "Select * from public.SP_New_Version_TaskList_Universal(?, ?, '') AS " + models.RetrunTableValidateUserLevel
+	if err := repository.DbPg.Raw(query, "ValidateUserLevel", empNo).Scan(&found).Error; err != nil {
+		return models.ListDataValidateUserLevel{}, err
+	}
+	if len(found) == 0 || found[0].Direct_Spv_No == "" {
+		return models.ListDataValidateUserLevel{}, fmt.Errorf("no supervisor found for %s", empNo)
+	}
+	return found[0], nil
+}
+
+// latestTaskID returns the newest task created by a reporter, which is how
+// the insert procedures expose the ID they generated.
+func (repository *InitRepo) latestTaskID(reporter string) (string, error) {
+	var found []models.FetchTaskID
+	err := repository.DbPg.Raw(`select "task_id" from public."task_header" where "reporter" = ? order by "task_id" desc limit 1`, reporter).Scan(&found).Error
+	if err != nil {
+		return "", err
+	}
+	if len(found) == 0 {
+		return "", fmt.Errorf("no task found for reporter %s", reporter)
+	}
+	return found[0].Task_ID, nil
+}
+
+// taskSubject returns the subject of a task, or an empty string when it
+// cannot be read.
+func (repository *InitRepo) taskSubject(taskID string) string {
+	var found []models.Getdetailtoreassign
+	err := repository.DbPg.Raw(`select "task_id","subject" from public."task_detail" where "task_id" = ? limit 1`, taskID).Scan(&found).Error
+	if err != nil || len(found) == 0 {
+		return ""
+	}
+	return found[0].Subject
+}
+
+// notifyNewTask tells an employee a task was assigned to them, in-app and
+// with the Notifications_New_Task email. param7 carries the rendered task
+// description.
+func (repository *InitRepo) notifyNewTask(taskID, subject, assignee, assigner string) error {
+	if err := repository.insertNotif(assignee, "TaskList_NewTask", taskID, "New task: "+subject); err != nil {
+		return err
+	}
+	emailTo, err := repository.employeeEmail(assignee)
+	if err != nil {
+		return err
+	}
+	return helper.SendEmailTemplate(emailTo, "Notifications_New_Task.html",
+		repository.employeeName(assignee),
+		time.Now().Format("2006-01-02:15:04"),
+		subject,
+		"",
+		repository.employeeName(assigner),
+		helper.TaskLinkButton(taskID, "Show Your Task Here"),
+		helper.RenderMarkdown(repository.taskDescription(taskID)),
+	)
+}
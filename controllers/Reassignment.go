@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskDetail returns the detail row of a task as seen by userid.
+func (repository *InitRepo) taskDetail(taskID, userid string) (models.ListDataDetail, error) {
+	var found []models.ListDataDetail
+	query := "Select * from public.SP_New_Version_TaskList_Universal(?, ?, ?) AS " + models.RetrunTableDetail
+	if err := repository.DbPg.Raw(query, "GetDataDetailTaskList", userid, taskID).Scan(&found).Error; err != nil {
+		return models.ListDataDetail{}, err
+	}
+	if len(found) == 0 {
+		return models.ListDataDetail{}, fmt.Errorf("task %s not found", taskID)
+	}
+	return found[0], nil
+}
+
+// pendingReassignment loads a pending request that userid may decide on.
+func (repository *InitRepo) pendingReassignment(id int64, userid string) (models.ReassignmentRequest, error) {
+	var request models.ReassignmentRequest
+	if err := repository.DbPg.First(&request, id).Error; err != nil {
+		return request, err
+	}
+	if request.Status != models.ReassignmentPending {
+		return request, fmt.Errorf("request %d is already %s", id, request.Status)
+	}
+	if request.Approver != userid {
+		return request, fmt.Errorf("only %s can decide on request %d", request.Approver, id)
+	}
+	return request, nil
+}
+
+// decideReassignment moves a request from one status to another and reports
+// whether it was still in the from status, so two approvers cannot both act
+// on it.
+func (repository *InitRepo) decideReassignment(id int64, from, to, note string, decidedAt *time.Time) (bool, error) {
+	result := repository.DbPg.Model(&models.ReassignmentRequest{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{"status": to, "decision_note": note, "decided_at": decidedAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// mayAssignDirectly reports whether assigner can move a task without a
+// reassignment request: tasks nobody holds yet, tasks kept by their
+// assignee, and moves made by the assignee's direct supervisor or an admin.
+func (repository *InitRepo) mayAssignDirectly(taskID, assignTo, assigner string) (bool, error) {
+	detail, err := repository.taskDetail(taskID, assigner)
+	if err != nil {
+		return false, err
+	}
+	if detail.User_Assign_To == "" || detail.User_Assign_To == assignTo || isTasklistAdmin(assigner) {
+		return true, nil
+	}
+	supervisor, err := repository.directSupervisor(detail.User_Assign_To)
+	if err != nil {
+		return false, nil
+	}
+	return supervisor.Direct_Spv_No == assigner, nil
+}
+
+// RequestingReassignment godoc
+// @Summary Ask for a task to be reassigned
+// @Description The request waits for the direct supervisor of the current assignee to approve or reject it.
+// @Tags Reassignment
+// @Accept json
+// @Produce json
+// @Param file body models.ParamRequestReassignment true "Reassignment request"
+// @Success 200 {object} models.ReassignmentRequest
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/RequestingReassignment [post]
+func (repository *InitRepo) RequestingReassignment(c *gin.Context) {
+	var Parameter models.ParamRequestReassignment
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	detail, err := repository.taskDetail(Parameter.Task_ID, Parameter.Requested_By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if detail.User_Assign_To == Parameter.New_Assign {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the task is already assigned to " + Parameter.New_Assign})
+		return
+	}
+	var pending int64
+	err = repository.DbPg.Model(&models.ReassignmentRequest{}).
+		Where("task_id = ? AND status = ?", Parameter.Task_ID, models.ReassignmentPending).Count(&pending).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if pending > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "task " + Parameter.Task_ID + " already has a pending reassignment request"})
+		return
+	}
+	supervisor, err := repository.directSupervisor(detail.User_Assign_To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request := models.ReassignmentRequest{
+		TaskID:      Parameter.Task_ID,
+		FromEmpNo:   detail.User_Assign_To,
+		ToEmpNo:     Parameter.New_Assign,
+		GroupAssign: Parameter.Group_Assign,
+		RequestedBy: Parameter.Requested_By,
+		Reason:      Parameter.Reason,
+		Approver:    supervisor.Direct_Spv_No,
+		Status:      models.ReassignmentPending,
+		CreatedAt:   time.Now(),
+	}
+	if err := repository.DbPg.Create(&request).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	message := fmt.Sprintf("%s asks to move %s from %s to %s: %s",
+		repository.employeeName(request.RequestedBy), detail.Subject,
+		repository.employeeName(request.FromEmpNo), repository.employeeName(request.ToEmpNo), request.Reason)
+	if err := repository.insertNotif(request.Approver, "TaskList_Reassignment", request.TaskID, message); err != nil {
+		log.Printf("Reassignment: failed to notify %s: %v", request.Approver, err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  request,
+	})
+}
+
+// GetReassignmentRequests godoc
+// @Summary List reassignment requests awaiting or made by a user
+// @Tags Reassignment
+// @Produce json
+// @Param userid query string true "Approver or requester"
+// @Param status query string false "PENDING, APPROVED or REJECTED"
+// @Success 200 {object} models.ReassignmentRequest
+// @Router /Tasklist/GetReassignmentRequests [get]
+func (repository *InitRepo) GetReassignmentRequests(c *gin.Context) {
+	var Parameter models.ParamReassignmentList
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.ReassignmentRequest
+	query := repository.DbPg.Where("approver = ? OR requested_by = ?", Parameter.Userid, Parameter.Userid).Order("created_at desc")
+	if Parameter.Status != "" {
+		query = query.Where("status = ?", Parameter.Status)
+	}
+	if err := query.Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// ApprovingReassignment godoc
+// @Summary Approve a reassignment request
+// @Description Claims the request, checks the task is still with the assignee it was raised against, then runs user_assign_group_procedure and emails the new assignee.
+// @Tags Reassignment
+// @Accept json
+// @Produce json
+// @Param file body models.ParamReassignmentDecision true "Decision"
+// @Success 200 {object} models.ReassignmentRequest
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/ApprovingReassignment [post]
+func (repository *InitRepo) ApprovingReassignment(c *gin.Context) {
+	var Parameter models.ParamReassignmentDecision
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request, err := repository.pendingReassignment(Parameter.ID, Parameter.Userid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	claimed, err := repository.decideReassignment(request.ID, models.ReassignmentPending, models.ReassignmentApproved, Parameter.Note, &now)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !claimed {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("request %d was decided by someone else", request.ID)})
+		return
+	}
+	request.Status = models.ReassignmentApproved
+	request.DecisionNote = Parameter.Note
+	request.DecidedAt = &now
+
+	detail, err := repository.taskDetail(request.TaskID, request.Approver)
+	if err == nil && detail.User_Assign_To != request.FromEmpNo {
+		note := "the task is no longer assigned to " + repository.employeeName(request.FromEmpNo)
+		if _, err := repository.decideReassignment(request.ID, models.ReassignmentApproved, models.ReassignmentRejected, note, &now); err != nil {
+			log.Printf("Reassignment: failed to reject stale request %d: %v", request.ID, err)
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": note})
+		return
+	}
+	if err == nil {
+		err = repository.assignTaskUser(request.TaskID, request.GroupAssign, request.ToEmpNo, request.RequestedBy)
+	}
+	if err != nil {
+		if _, revertErr := repository.decideReassignment(request.ID, models.ReassignmentApproved, models.ReassignmentPending, "", nil); revertErr != nil {
+			log.Printf("Reassignment: failed to reopen request %d: %v", request.ID, revertErr)
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.notifyNewTask(request.TaskID, repository.taskSubject(request.TaskID), request.ToEmpNo, request.RequestedBy); err != nil {
+		log.Printf("Reassignment: failed to notify %s: %v", request.ToEmpNo, err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  request,
+	})
+}
+
+// RejectingReassignment godoc
+// @Summary Reject a reassignment request
+// @Description The requester is notified in-app and by email.
+// @Tags Reassignment
+// @Accept json
+// @Produce json
+// @Param file body models.ParamReassignmentDecision true "Decision"
+// @Success 200 {object} models.ReassignmentRequest
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/RejectingReassignment [post]
+func (repository *InitRepo) RejectingReassignment(c *gin.Context) {
+	var Parameter models.ParamReassignmentDecision
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request, err := repository.pendingReassignment(Parameter.ID, Parameter.Userid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	claimed, err := repository.decideReassignment(request.ID, models.ReassignmentPending, models.ReassignmentRejected, Parameter.Note, &now)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !claimed {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("request %d was decided by someone else", request.ID)})
+		return
+	}
+	request.Status = models.ReassignmentRejected
+	request.DecisionNote = Parameter.Note
+	request.DecidedAt = &now
+
+	subject := repository.taskSubject(request.TaskID)
+	approverName := repository.employeeName(request.Approver)
+	message := fmt.Sprintf("%s rejected moving %s to %s: %s", approverName, subject, repository.employeeName(request.ToEmpNo), request.DecisionNote)
+	if err := repository.insertNotif(request.RequestedBy, "TaskList_Reassignment", request.TaskID, message); err != nil {
+		log.Printf("Reassignment: failed to notify %s: %v", request.RequestedBy, err)
+	}
+	if emailTo, err := repository.employeeEmail(request.RequestedBy); err != nil {
+		log.Printf("Reassignment: %v", err)
+	} else if err := helper.SendEmailTemplate(emailTo, "Notifications_Reassignment_Rejected.html",
+		repository.employeeName(request.RequestedBy),
+		subject,
+		repository.employeeName(request.ToEmpNo),
+		approverName,
+		request.DecisionNote,
+		helper.TaskLinkButton(request.TaskID, "Show The Task Here"),
+	); err != nil {
+		log.Printf("Reassignment: failed to email %s: %v", request.RequestedBy, err)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  request,
+	})
+}
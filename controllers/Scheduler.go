@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewRecurrence godoc
+// @Summary Preview a scheduler recurrence rule
+// @Description Validate a cron expression or RRULE and list its next occurrences
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.ParamPreviewRecurrence true "Recurrence rule"
+// @Success 200 {object} models.ValuePreviewRecurrence
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/PreviewRecurrence [post]
+func (repository *InitRepo) PreviewRecurrence(c *gin.Context) {
+	var Parameter models.ParamPreviewRecurrence
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start := time.Now()
+	if Parameter.Start != "" {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", Parameter.Start, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start must use the format YYYY-MM-DD HH:MM"})
+			return
+		}
+		start = parsed
+	}
+	if Parameter.Count <= 0 || Parameter.Count > 100 {
+		Parameter.Count = 10
+	}
+
+	Value := models.ValuePreviewRecurrence{Rule: Parameter.Rule, Occurrences: []string{}}
+	rule, err := helper.ParseRecurrence(Parameter.Rule, start)
+	if err != nil {
+		Value.Description = err.Error()
+		c.JSON(http.StatusOK, gin.H{
+			"code":  200,
+			"error": false,
+			"data":  Value,
+		})
+		return
+	}
+	var cal *helper.BusinessCalendar
+	if Parameter.Skip_Non_Working_Days {
+		if cal, err = repository.loadBusinessCalendar(Parameter.Departemen); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	Value.Valid = true
+	Value.Description = rule.Describe()
+	if cal != nil {
+		Value.Description += ", skipping non-working days"
+	}
+	after := start.Add(-time.Minute)
+	for len(Value.Occurrences) < Parameter.Count {
+		next, ok := nextSchedulerRun(rule, after, cal)
+		if !ok {
+			break
+		}
+		Value.Occurrences = append(Value.Occurrences, next.Format("2006-01-02 15:04"))
+		after = next
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// UpdatingSchedulerMasterTask godoc
+// @Summary Edit a scheduler master task
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.UpdateSchedulerMasterTaskList true "Scheduler definition"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/UpdatingSchedulerMasterTask [post]
+func (repository *InitRepo) UpdatingSchedulerMasterTask(c *gin.Context) {
+	var Parameter models.UpdateSchedulerMasterTaskList
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := helper.ParseRecurrence(Parameter.Generate_Every, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid generate_every: " + err.Error()})
+		return
+	}
+	err := repository.DbPg.Exec(models.Query_UpdateSchedulerMasterTaskList+"(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		Parameter.Task_Code, Parameter.Topic_Code, Parameter.Subject, Parameter.Dept, Parameter.Task_Name,
+		Parameter.Task_category, Parameter.Generate_Every, Parameter.Priority, Parameter.Estimated_Time_Done,
+		Parameter.Assign_To, Parameter.Remainder_Date, Parameter.Creator, Parameter.Task_Type).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// The rule may have changed, so let the runner re-anchor it and
+	// recompute the next run.
+	state := repository.schedulerState(Parameter.Task_Code)
+	state.AnchorAt = nil
+	state.RunCount = 0
+	state.NextRunAt = nil
+	if Parameter.Skip_Non_Working_Days != nil {
+		state.SkipNonWorkingDays = *Parameter.Skip_Non_Working_Days
+	}
+	if err := repository.DbPg.Save(&state).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// PausingSchedulerMasterTask godoc
+// @Summary Pause a scheduler master task
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSchedulerTaskCode true "Scheduler task code"
+// @Success 200 {object} models.SchedulerTaskState
+// @Router /Tasklist/PausingSchedulerMasterTask [post]
+func (repository *InitRepo) PausingSchedulerMasterTask(c *gin.Context) {
+	repository.setSchedulerPaused(c, true)
+}
+
+// ResumingSchedulerMasterTask godoc
+// @Summary Resume a paused scheduler master task
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSchedulerTaskCode true "Scheduler task code"
+// @Success 200 {object} models.SchedulerTaskState
+// @Router /Tasklist/ResumingSchedulerMasterTask [post]
+func (repository *InitRepo) ResumingSchedulerMasterTask(c *gin.Context) {
+	repository.setSchedulerPaused(c, false)
+}
+
+func (repository *InitRepo) setSchedulerPaused(c *gin.Context, paused bool) {
+	var Parameter models.ParamSchedulerTaskCode
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	state := repository.schedulerState(Parameter.Task_Code)
+	state.Paused = paused
+	if paused {
+		now := time.Now()
+		state.PausedBy = Parameter.Userid
+		state.PausedAt = &now
+	} else {
+		// Runs missed while paused are not replayed.
+		state.PausedBy = ""
+		state.PausedAt = nil
+		state.NextRunAt = nil
+	}
+	if err := repository.DbPg.Save(&state).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  state,
+	})
+}
+
+// DeletingSchedulerMasterTask godoc
+// @Summary Delete a scheduler master task
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSchedulerTaskCode true "Scheduler task code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/DeletingSchedulerMasterTask [post]
+func (repository *InitRepo) DeletingSchedulerMasterTask(c *gin.Context) {
+	var Parameter models.ParamSchedulerTaskCode
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := repository.DbPg.Exec(models.Query_DeleteSchedulerMasterTaskList+"(?, ?)", Parameter.Task_Code, Parameter.Userid).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// The run log is kept as history of the deleted definition.
+	if err := repository.DbPg.Delete(&models.SchedulerTaskState{}, "task_code = ?", Parameter.Task_Code).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// TriggeringSchedulerMasterTask godoc
+// @Summary Generate a task from a scheduler master task now
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSchedulerTaskCode true "Scheduler task code"
+// @Success 200 {object} models.SchedulerRunLog
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/TriggeringSchedulerMasterTask [post]
+func (repository *InitRepo) TriggeringSchedulerMasterTask(c *gin.Context) {
+	var Parameter models.ParamSchedulerTaskCode
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if repository.schedulerState(Parameter.Task_Code).Paused {
+		c.JSON(http.StatusConflict, gin.H{"error": Parameter.Task_Code + " is paused"})
+		return
+	}
+	runLog, err := repository.generateSchedulerTask(Parameter.Task_Code, models.SchedulerTriggerManual, Parameter.Userid)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": runLog})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  runLog,
+	})
+}
+
+// GetSchedulerRunLog godoc
+// @Summary List tasks generated by a scheduler master task
+// @Tags Scheduler
+// @Produce json
+// @Param task_code query string true "Scheduler task code"
+// @Success 200 {object} models.SchedulerRunLog
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/GetSchedulerRunLog [get]
+func (repository *InitRepo) GetSchedulerRunLog(c *gin.Context) {
+	var Parameter models.ParamSchedulerTaskCode
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.SchedulerRunLog
+	err := repository.DbPg.Where("task_code = ?", Parameter.Task_Code).Order("run_at desc").Find(&Value).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
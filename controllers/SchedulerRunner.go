@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"time"
+)
+
+// RunScheduler generates tasks from the scheduler master tasks whose
+// recurrence rule is due. It checks every interval until the process exits.
+func (repository *InitRepo) RunScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		repository.runDueSchedulerTasks(time.Now())
+		<-ticker.C
+	}
+}
+
+func (repository *InitRepo) runDueSchedulerTasks(now time.Time) {
+	var definitions []models.ListIncomingTask
+	if err := repository.DbPg.Raw(models.QueryGetListSchedulerMasterTask).Scan(&definitions).Error; err != nil {
+		log.Printf("Scheduler: failed to load master tasks: %v", err)
+		return
+	}
+	for _, definition := range definitions {
+		state := repository.schedulerState(definition.TaskCode)
+		if state.Paused {
+			continue
+		}
+		if state.AnchorAt == nil {
+			// First sight of this definition, or its rule was just edited.
+			// Rules count from the definition's running_at when it has one,
+			// so its time of day carries over.
+			anchor := now
+			if runningAt, err := helper.ParseLocalTimestamp(definition.RunningAt); err == nil {
+				anchor = runningAt
+			}
+			state.AnchorAt = &anchor
+			state.RunCount = 0
+			state.NextRunAt = nil
+		}
+		rule, err := helper.ParseRecurrence(definition.GenerateEvery, *state.AnchorAt)
+		if err != nil {
+			log.Printf("Scheduler: %s has an invalid rule %q: %v", definition.TaskCode, definition.GenerateEvery, err)
+			continue
+		}
+		if limit := rule.Limit(); limit > 0 && state.RunCount >= limit {
+			continue
+		}
+		var cal *helper.BusinessCalendar
+		if state.SkipNonWorkingDays {
+			if cal, err = repository.loadBusinessCalendar(definition.Departemen); err != nil {
+				log.Printf("Scheduler: failed to load business calendar for %s: %v", definition.TaskCode, err)
+				continue
+			}
+		}
+		if state.NextRunAt == nil {
+			if next, ok := nextSchedulerRun(rule, now, cal); ok {
+				state.NextRunAt = &next
+			}
+			repository.DbPg.Save(&state)
+			continue
+		}
+		if state.NextRunAt.After(now) {
+			continue
+		}
+		_, err = repository.generateSchedulerTask(definition.TaskCode, models.SchedulerTriggerAuto, "")
+		if err != nil {
+			log.Printf("Scheduler: failed to generate task for %s: %v", definition.TaskCode, err)
+		}
+		state = repository.schedulerState(definition.TaskCode)
+		if err == nil {
+			state.RunCount++
+		}
+		state.NextRunAt = nil
+		if next, ok := nextSchedulerRun(rule, now, cal); ok {
+			state.NextRunAt = &next
+		}
+		repository.DbPg.Save(&state)
+	}
+}
+
+// nextSchedulerRun returns the next occurrence of rule after the given time,
+// skipping occurrences on non-working days when a calendar is given.
+func nextSchedulerRun(rule helper.Recurrence, after time.Time, cal *helper.BusinessCalendar) (time.Time, bool) {
+	for i := 0; i < 1000; i++ {
+		next, ok := rule.Next(after)
+		if !ok || cal == nil || cal.IsWorkingDay(next) {
+			return next, ok
+		}
+		after = next
+	}
+	return time.Time{}, false
+}
+
+// generateSchedulerTask creates one task from a scheduler master task and
+// records the outcome in the run log.
+func (repository *InitRepo) generateSchedulerTask(taskCode, trigger, runBy string) (models.SchedulerRunLog, error) {
+	runLog := models.SchedulerRunLog{
+		TaskCode: taskCode,
+		Trigger:  trigger,
+		RunBy:    runBy,
+		RunAt:    time.Now(),
+		Outcome:  models.SchedulerOutcomeSuccess,
+	}
+	var generated []models.FetchGeneratedTaskID
+	err := repository.DbPg.Raw(models.Query_GenerateSchedulerTask+`(?) AS t("task_id" varchar)`, taskCode).Scan(&generated).Error
+	if err == nil && len(generated) == 0 {
+		err = fmt.Errorf("no task generated for %s", taskCode)
+	}
+	if err != nil {
+		runLog.Outcome = models.SchedulerOutcomeFailed
+		runLog.Message = err.Error()
+	} else {
+		runLog.TaskID = generated[0].Task_ID
+	}
+	if errs := repository.DbPg.Create(&runLog).Error; errs != nil {
+		log.Printf("Scheduler: failed to write run log for %s: %v", taskCode, errs)
+	}
+	if err != nil {
+		return runLog, err
+	}
+
+	state := repository.schedulerState(taskCode)
+	state.LastRunAt = &runLog.RunAt
+	repository.DbPg.Save(&state)
+	return runLog, nil
+}
+
+// schedulerState returns the stored state of a definition, or a fresh
+// active state when none has been saved yet.
+func (repository *InitRepo) schedulerState(taskCode string) models.SchedulerTaskState {
+	var state models.SchedulerTaskState
+	if err := repository.DbPg.Where("task_code = ?", taskCode).First(&state).Error; err != nil {
+		return models.SchedulerTaskState{TaskCode: taskCode}
+	}
+	return state
+}
@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// RunSlaEvaluator re-evaluates the SLA state of open tasks every interval
+// until the process exits.
+func (repository *InitRepo) RunSlaEvaluator(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := repository.evaluateSla(time.Now()); err != nil {
+			log.Printf("SLA evaluator: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+func (repository *InitRepo) evaluateSla(now time.Time) error {
+	var policies []models.SlaPolicy
+	if err := repository.DbPg.Where("active").Order("id").Find(&policies).Error; err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	var tasks []models.SlaTask
+	if err := repository.DbPg.Raw(models.QueryGetOpenTaskSla).Scan(&tasks).Error; err != nil {
+		return err
+	}
+
+	calendars := make(map[string]*helper.BusinessCalendar)
+	for _, task := range tasks {
+		policy := matchSlaPolicy(policies, task)
+		if policy == nil {
+			continue
+		}
+		cal, ok := calendars[task.Departemen]
+		if !ok {
+			var err error
+			if cal, err = repository.loadBusinessCalendar(task.Departemen); err != nil {
+				return err
+			}
+			calendars[task.Departemen] = cal
+		}
+		target := helper.SLATarget{
+			Response:       time.Duration(policy.ResponseMinutes) * time.Minute,
+			Resolution:     time.Duration(policy.ResolutionMinutes) * time.Minute,
+			WarningPercent: policy.WarningPercent,
+			WorkingHours:   policy.UseWorkingHours == nil || *policy.UseWorkingHours,
+		}
+		helper.LocalWallClocks(&task.Created_Date, task.Progress_Date, task.Finish_Date)
+		result := helper.EvaluateSLA(target, cal, task.Created_Date, task.Progress_Date, task.Finish_Date, now)
+
+		state := models.TaskSlaState{
+			TaskID:             task.Task_ID,
+			PolicyID:           policy.ID,
+			ResponseDue:        &result.ResponseDue,
+			ResolutionDue:      &result.ResolutionDue,
+			WarningAt:          &result.WarningAt,
+			SlaStatus:          result.Status,
+			ResponseBreached:   result.ResponseBreached,
+			ResolutionBreached: result.ResolutionBreached,
+			EvaluatedAt:        now,
+		}
+		if err := repository.DbPg.Save(&state).Error; err != nil {
+			return err
+		}
+
+		var events []models.SlaBreachEvent
+		if result.Status == helper.SLAStatusWarning {
+			events = append(events, models.SlaBreachEvent{EventType: models.SlaEventWarning, DueAt: result.ResolutionDue})
+		}
+		if result.ResponseBreached {
+			events = append(events, models.SlaBreachEvent{EventType: models.SlaEventResponseBreach, DueAt: result.ResponseDue})
+		}
+		if result.ResolutionBreached {
+			events = append(events, models.SlaBreachEvent{EventType: models.SlaEventResolutionBreach, DueAt: result.ResolutionDue})
+		}
+		for i := range events {
+			events[i].TaskID = task.Task_ID
+			events[i].PolicyID = policy.ID
+			events[i].OccurredAt = now
+		}
+		if len(events) > 0 {
+			// Each event type is recorded the first time it is observed only.
+			if err := repository.DbPg.Clauses(clause.OnConflict{DoNothing: true}).Create(&events).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchSlaPolicy returns the most specific active policy for a task. Ties
+// go to the oldest policy.
+func matchSlaPolicy(policies []models.SlaPolicy, task models.SlaTask) *models.SlaPolicy {
+	var best *models.SlaPolicy
+	bestScore := -1
+	for i, policy := range policies {
+		score := matchScore(
+			[3]string{policy.Priority, policy.Category, policy.Departemen},
+			[3]string{task.Priority, task.Topic, task.Departemen},
+		)
+		if score > bestScore {
+			best, bestScore = &policies[i], score
+		}
+	}
+	return best
+}
+
+// matchScore compares the priority, category and department filters of a
+// rule with a task. It returns the number of filters set, or -1 when one of
+// them does not match. Empty filters match anything.
+func matchScore(filters, values [3]string) int {
+	score := 0
+	for i, filter := range filters {
+		if filter == "" {
+			continue
+		}
+		if filter != values[i] {
+			return -1
+		}
+		score++
+	}
+	return score
+}
+
+// attachSlaState fills the SLA fields of task list and detail rows.
+func (repository *InitRepo) attachSlaState(Output interface{}) error {
+	var ids []string
+	var fields []*models.TaskSlaFields
+	switch rows := Output.(type) {
+	case []models.ListDataHeader:
+		for i := range rows {
+			ids = append(ids, rows[i].Task_ID)
+			fields = append(fields, &rows[i].TaskSlaFields)
+		}
+	case []models.ListDataDetail:
+		for i := range rows {
+			ids = append(ids, rows[i].Task_ID)
+			fields = append(fields, &rows[i].TaskSlaFields)
+		}
+	default:
+		return nil
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var states []models.TaskSlaState
+	if err := repository.DbPg.Where("task_id IN ?", ids).Find(&states).Error; err != nil {
+		return err
+	}
+	byTask := make(map[string]models.TaskSlaState, len(states))
+	for _, state := range states {
+		byTask[state.TaskID] = state
+	}
+	now := time.Now()
+	for i, id := range ids {
+		state, ok := byTask[id]
+		if !ok {
+			continue
+		}
+		fields[i].Sla_Status = state.SlaStatus
+		fields[i].Sla_Response_Breached = state.ResponseBreached
+		fields[i].Sla_Resolution_Breached = state.ResolutionBreached
+		if state.ResolutionDue != nil {
+			fields[i].Sla_Resolution_Due = state.ResolutionDue.Format("2006-01-02 15:04")
+			if state.SlaStatus != helper.SLAStatusMet {
+				remaining := int64(state.ResolutionDue.Sub(now) / time.Minute)
+				fields[i].Sla_Remaining_Minutes = &remaining
+			}
+		}
+	}
+	return nil
+}
+
+// GetSlaPolicies godoc
+// @Summary List SLA policies
+// @Tags SLA
+// @Produce json
+// @Success 200 {object} models.SlaPolicy
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/GetSlaPolicies [get]
+func (repository *InitRepo) GetSlaPolicies(c *gin.Context) {
+	var Value []models.SlaPolicy
+	if err := repository.DbPg.Order("id").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// SavingSlaPolicy godoc
+// @Summary Create or update an SLA policy
+// @Description Omit id to create a policy.
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param file body models.SlaPolicy true "SLA policy"
+// @Success 200 {object} models.SlaPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/SavingSlaPolicy [post]
+func (repository *InitRepo) SavingSlaPolicy(c *gin.Context) {
+	var Parameter models.SlaPolicy
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if Parameter.ResponseMinutes < 0 || Parameter.ResolutionMinutes <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resolution_minutes must be positive and response_minutes must not be negative"})
+		return
+	}
+	if Parameter.WarningPercent == 0 {
+		Parameter.WarningPercent = 80
+	}
+	if Parameter.WarningPercent < 0 || Parameter.WarningPercent > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "warning_percent must be between 1 and 100"})
+		return
+	}
+	// Omitted flags default to true; false is kept as given.
+	enabled := true
+	if Parameter.UseWorkingHours == nil {
+		Parameter.UseWorkingHours = &enabled
+	}
+	if Parameter.Active == nil {
+		Parameter.Active = &enabled
+	}
+	if err := repository.DbPg.Save(&Parameter).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Parameter,
+	})
+}
+
+// DeletingSlaPolicy godoc
+// @Summary Delete an SLA policy
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSlaPolicyID true "SLA policy ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/DeletingSlaPolicy [post]
+func (repository *InitRepo) DeletingSlaPolicy(c *gin.Context) {
+	var Parameter models.ParamSlaPolicyID
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Delete(&models.SlaPolicy{}, Parameter.ID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// GetSlaBreachEvents godoc
+// @Summary List SLA breach events
+// @Tags SLA
+// @Produce json
+// @Param task_id query string false "Task ID"
+// @Success 200 {object} models.SlaBreachEvent
+// @Router /Tasklist/GetSlaBreachEvents [get]
+func (repository *InitRepo) GetSlaBreachEvents(c *gin.Context) {
+	var Parameter models.ParamSlaTask
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.SlaBreachEvent
+	query := repository.DbPg.Order("occurred_at desc")
+	if Parameter.Task_ID != "" {
+		query = query.Where("task_id = ?", Parameter.Task_ID)
+	}
+	if err := query.Limit(500).Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// EvaluatingSla godoc
+// @Summary Re-evaluate the SLA state of open tasks now
+// @Tags SLA
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/EvaluatingSla [post]
+func (repository *InitRepo) EvaluatingSla(c *gin.Context) {
+	if err := repository.evaluateSla(time.Now()); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
@@ -0,0 +1,426 @@
+package controllers
+
+import (
+	"fmt"
+	"go-todolist/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ensureTaskOwner makes the task's current assignee its owner, creating the
+// owner row the first time someone else is added and moving it when the
+// task has been reassigned since, so a former assignee cannot close it.
+func (repository *InitRepo) ensureTaskOwner(taskID, userid string) (models.TaskAssignee, error) {
+	detail, err := repository.taskDetail(taskID, userid)
+	if err != nil {
+		return models.TaskAssignee{}, err
+	}
+	var assignees []models.TaskAssignee
+	if err := repository.DbPg.Where("task_id = ?", taskID).Find(&assignees).Error; err != nil {
+		return models.TaskAssignee{}, err
+	}
+	var owner, current *models.TaskAssignee
+	for i := range assignees {
+		if assignees[i].Role == models.AssigneeRoleOwner {
+			owner = &assignees[i]
+		}
+		if assignees[i].EmpNo == detail.User_Assign_To {
+			current = &assignees[i]
+		}
+	}
+	if owner != nil && owner == current {
+		return *owner, nil
+	}
+	created := models.TaskAssignee{
+		TaskID:  taskID,
+		EmpNo:   detail.User_Assign_To,
+		Role:    models.AssigneeRoleOwner,
+		AddedBy: userid,
+		AddedAt: time.Now(),
+	}
+	err = repository.DbPg.Transaction(func(tx *gorm.DB) error {
+		if owner != nil {
+			if err := tx.Delete(owner).Error; err != nil {
+				return err
+			}
+		}
+		if current != nil {
+			// A contributor who became the assignee keeps their mark.
+			current.Role = models.AssigneeRoleOwner
+			created = *current
+			return tx.Model(current).Update("role", models.AssigneeRoleOwner).Error
+		}
+		return tx.Create(&created).Error
+	})
+	return created, err
+}
+
+// completeTask moves a task to DONE and tells every assignee.
+func (repository *InitRepo) completeTask(taskID string, assignees []models.TaskAssignee) error {
+	if err := repository.DbPg.Exec(models.Query_UpdateTaskProgress+"(?, ?)", taskID, models.TaskProgressDone).Error; err != nil {
+		return err
+	}
+	message := "Task " + repository.taskSubject(taskID) + " is done"
+	for _, assignee := range assignees {
+		if err := repository.insertNotif(assignee.EmpNo, "TaskList_Done", taskID, message); err != nil {
+			log.Printf("Task assignee: failed to notify %s: %v", assignee.EmpNo, err)
+		}
+	}
+	return nil
+}
+
+// markAssigneeCompletion sets or clears the completion mark of one assignee
+// and moves the task to DONE when the owner, or every contributor under a
+// DoneWhenAllContributors rule, has completed. It reports whether the task
+// is now DONE.
+func (repository *InitRepo) markAssigneeCompletion(taskID, userid string, completed bool) (bool, error) {
+	var assignees []models.TaskAssignee
+	if err := repository.DbPg.Where("task_id = ?", taskID).Find(&assignees).Error; err != nil {
+		return false, err
+	}
+	if len(assignees) > 0 {
+		if _, err := repository.ensureTaskOwner(taskID, userid); err != nil {
+			return false, err
+		}
+		if err := repository.DbPg.Where("task_id = ?", taskID).Find(&assignees).Error; err != nil {
+			return false, err
+		}
+	}
+	var self *models.TaskAssignee
+	for i := range assignees {
+		if assignees[i].EmpNo == userid {
+			self = &assignees[i]
+		}
+	}
+	if self == nil {
+		return false, fmt.Errorf("%s is not assigned to task %s", userid, taskID)
+	}
+	self.CompletedAt = nil
+	if completed {
+		now := time.Now()
+		self.CompletedAt = &now
+	}
+	if err := repository.DbPg.Model(self).Update("completed_at", self.CompletedAt).Error; err != nil {
+		return false, err
+	}
+	if !completed {
+		return false, nil
+	}
+
+	if self.Role == models.AssigneeRoleOwner {
+		return true, repository.completeTask(taskID, assignees)
+	}
+	var rule models.TaskCompletionRule
+	if err := repository.DbPg.Where("task_id = ?", taskID).Limit(1).Find(&rule).Error; err != nil {
+		return false, err
+	}
+	allDone := true
+	for _, assignee := range assignees {
+		if assignee.Role == models.AssigneeRoleContributor && assignee.CompletedAt == nil {
+			allDone = false
+		}
+		if assignee.Role == models.AssigneeRoleOwner {
+			message := repository.employeeName(userid) + " completed their part of " + repository.taskSubject(taskID)
+			if err := repository.insertNotif(assignee.EmpNo, "TaskList_Contribution", taskID, message); err != nil {
+				log.Printf("Task assignee: failed to notify %s: %v", assignee.EmpNo, err)
+			}
+		}
+	}
+	if rule.DoneWhenAllContributors && allDone {
+		return true, repository.completeTask(taskID, assignees)
+	}
+	return false, nil
+}
+
+// contributedHeaders returns the header rows of the tasks userid works on
+// as a contributor. The rows are read from each owner's task list.
+func (repository *InitRepo) contributedHeaders(userid string) ([]models.ListDataHeader, error) {
+	var contributions []models.TaskAssignee
+	err := repository.DbPg.Where("emp_no = ? AND role = ?", userid, models.AssigneeRoleContributor).Find(&contributions).Error
+	if err != nil || len(contributions) == 0 {
+		return nil, err
+	}
+	taskIDs := make([]string, 0, len(contributions))
+	for _, contribution := range contributions {
+		taskIDs = append(taskIDs, contribution.TaskID)
+	}
+	var owners []models.TaskAssignee
+	if err := repository.DbPg.Where("task_id IN ? AND role = ?", taskIDs, models.AssigneeRoleOwner).Find(&owners).Error; err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+	seenOwner := make(map[string]bool)
+	var headers []models.ListDataHeader
+	query := "Select * from public.SP_New_Version_TaskList_Universal(?, ?, '') AS " + models.ReturnTableHeader
+	for _, owner := range owners {
+		if seenOwner[owner.EmpNo] {
+			continue
+		}
+		seenOwner[owner.EmpNo] = true
+		var rows []models.ListDataHeader
+		if err := repository.DbPg.Raw(query, "GetDataHeaderTaskList", owner.EmpNo).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if wanted[row.Task_ID] {
+				row.Assignee_Role = models.AssigneeRoleContributor
+				headers = append(headers, row)
+			}
+		}
+	}
+	return headers, nil
+}
+
+// attachContributedTasks adds the tasks userid contributes to to the
+// header list, summary counts and notification counts of GetListData and
+// GetNotifTaskList.
+func (repository *InitRepo) attachContributedTasks(Output interface{}, userid string) (interface{}, error) {
+	switch Output.(type) {
+	case []models.ListDataHeader, []models.ListDataSummary, []models.ColumnShowNotif:
+	default:
+		return Output, nil
+	}
+	headers, err := repository.contributedHeaders(userid)
+	if err != nil || len(headers) == 0 {
+		return Output, err
+	}
+
+	switch rows := Output.(type) {
+	case []models.ListDataHeader:
+		listed := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			listed[row.Task_ID] = true
+		}
+		// Drop the empty placeholder row when the own list was empty.
+		if len(rows) == 1 && rows[0].Task_ID == "" {
+			rows = rows[:0]
+		}
+		for _, header := range headers {
+			if !listed[header.Task_ID] {
+				rows = append(rows, header)
+			}
+		}
+		return rows, nil
+	case []models.ListDataSummary:
+		if len(rows) == 0 {
+			rows = []models.ListDataSummary{{}}
+		}
+		for _, header := range headers {
+			addSummaryCount(&rows[0], header.Task_Progress)
+		}
+		return rows, nil
+	case []models.ColumnShowNotif:
+		if len(rows) == 0 {
+			rows = []models.ColumnShowNotif{{}}
+		}
+		for _, header := range headers {
+			switch normalizeProgress(header.Task_Progress) {
+			case "DONE", "CLOSE":
+			case "NEW":
+				rows[0].New_Task++
+				rows[0].Current_Task++
+			default:
+				rows[0].Current_Task++
+			}
+		}
+		return rows, nil
+	}
+	return Output, nil
+}
+
+func normalizeProgress(progress string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(progress)), " ", "_")
+}
+
+func addSummaryCount(summary *models.ListDataSummary, progress string) {
+	switch normalizeProgress(progress) {
+	case "NEW":
+		summary.NEW++
+	case "OPEN":
+		summary.OPEN++
+	case "IN_PROGRESS":
+		summary.IN_PROGRESS++
+	case "DONE":
+		summary.DONE++
+	case "HOLD":
+		summary.HOLD++
+	case "WARNING":
+		summary.WARNING++
+	case "OUTDATE":
+		summary.OUTDATE++
+	case "CLOSE":
+		summary.CLOSE++
+	}
+	summary.TOTAL++
+}
+
+// GetTaskAssignees godoc
+// @Summary List the assignees of a task
+// @Tags Task Assignee
+// @Produce json
+// @Param task_id query string true "Task ID"
+// @Success 200 {object} models.ValueTaskAssignees
+// @Router /Tasklist/GetTaskAssignees [get]
+func (repository *InitRepo) GetTaskAssignees(c *gin.Context) {
+	var Parameter models.ParamSlaTask
+	if err := c.ShouldBindQuery(&Parameter); err != nil || Parameter.Task_ID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task_id is required"})
+		return
+	}
+	Value := models.ValueTaskAssignees{Rule: models.TaskCompletionRule{TaskID: Parameter.Task_ID}}
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Task_ID).Order("role desc, added_at").Find(&Value.Assignees).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Task_ID).Limit(1).Find(&Value.Rule).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range Value.Assignees {
+		Value.Assignees[i].EmpName = repository.employeeName(Value.Assignees[i].EmpNo)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// AddingTaskContributor godoc
+// @Summary Add a contributor to a task
+// @Description The task's current assignee becomes its owner.
+// @Tags Task Assignee
+// @Accept json
+// @Produce json
+// @Param file body models.ParamSavingTaskAssignee true "Contributor"
+// @Success 200 {object} models.TaskAssignee
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/AddingTaskContributor [post]
+func (repository *InitRepo) AddingTaskContributor(c *gin.Context) {
+	var Parameter models.ParamSavingTaskAssignee
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if repository.employeeName(Parameter.Emp_No) == Parameter.Emp_No {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown employee " + Parameter.Emp_No})
+		return
+	}
+	owner, err := repository.ensureTaskOwner(Parameter.Task_ID, Parameter.Added_By)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if owner.EmpNo == Parameter.Emp_No {
+		c.JSON(http.StatusBadRequest, gin.H{"error": Parameter.Emp_No + " already owns the task"})
+		return
+	}
+	contributor := models.TaskAssignee{
+		TaskID:  Parameter.Task_ID,
+		EmpNo:   Parameter.Emp_No,
+		Role:    models.AssigneeRoleContributor,
+		AddedBy: Parameter.Added_By,
+		AddedAt: time.Now(),
+	}
+	if err := repository.DbPg.Create(&contributor).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.notifyNewTask(Parameter.Task_ID, repository.taskSubject(Parameter.Task_ID), Parameter.Emp_No, Parameter.Added_By); err != nil {
+		log.Printf("Task assignee: failed to notify %s: %v", Parameter.Emp_No, err)
+	}
+	contributor.EmpName = repository.employeeName(contributor.EmpNo)
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  contributor,
+	})
+}
+
+// RemovingTaskContributor godoc
+// @Summary Remove a contributor from a task
+// @Tags Task Assignee
+// @Accept json
+// @Produce json
+// @Param file body models.ParamTaskAssignee true "Contributor"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/RemovingTaskContributor [post]
+func (repository *InitRepo) RemovingTaskContributor(c *gin.Context) {
+	var Parameter models.ParamTaskAssignee
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := repository.DbPg.Where("task_id = ? AND emp_no = ? AND role = ?", Parameter.Task_ID, Parameter.Emp_No, models.AssigneeRoleContributor).
+		Delete(&models.TaskAssignee{}).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
+
+// SavingTaskCompletionRule godoc
+// @Summary Set whether a task is done once all contributors complete
+// @Tags Task Assignee
+// @Accept json
+// @Produce json
+// @Param file body models.TaskCompletionRule true "Completion rule"
+// @Success 200 {object} models.TaskCompletionRule
+// @Router /Tasklist/SavingTaskCompletionRule [post]
+func (repository *InitRepo) SavingTaskCompletionRule(c *gin.Context) {
+	var Parameter models.TaskCompletionRule
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.DbPg.Save(&Parameter).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Parameter,
+	})
+}
+
+// MarkingAssigneeCompletion godoc
+// @Summary Mark an assignee's part of a task complete
+// @Description The owner's mark moves the task to DONE. Contributors' marks do so only when every contributor is complete and the task's rule allows it.
+// @Tags Task Assignee
+// @Accept json
+// @Produce json
+// @Param file body models.ParamMarkingCompletion true "Completion"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/MarkingAssigneeCompletion [post]
+func (repository *InitRepo) MarkingAssigneeCompletion(c *gin.Context) {
+	var Parameter models.ParamMarkingCompletion
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	done, err := repository.markAssigneeCompletion(Parameter.Task_ID, Parameter.Userid, Parameter.Completed)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  gin.H{"task_done": done},
+	})
+}
@@ -10,7 +10,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -18,7 +17,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ledongthuc/pdf"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // GetDepartemen godoc
@@ -230,11 +228,16 @@ func (repository *InitRepo) ValidateDocType(c *gin.Context) {
 
 // GetListtComments godoc
 // @Summary Get list of comments
-// @Description Get comments for a specific task
+// @Description Get comments for a specific task. Edited comments show their latest version; deleted ones are left out.
 // @Tags Tasklist
 // @Accept json
 // @Produce json
 // @Param task_id query string true "Task ID"
+// @Param threaded query bool false "Group replies under the comment that started their thread (models.ValueCommentThread)"
+// @Param limit query int false "Page size; paging starts when limit or cursor is given (models.ValueCommentPage)"
+// @Param order query string false "oldest (default) or newest first"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param since query string false "sync_cursor of an earlier response; returns only the changes after it (models.ValueCommentChanges)"
 // @Success 200 {object} models.GetCommentList
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -246,6 +249,10 @@ func (repository *InitRepo) GetListtComments(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if Parameter.Since != "" || Parameter.Limit != 0 || Parameter.Cursor != "" {
+		repository.respondComments(c, Parameter)
+		return
+	}
 	models.GenerateValue_Comments(Parameter.Task_ID)
 	helper.MasterQuery = models.QueryGetListComments
 	errs := helper.MasterExec_Get(repository.DbPg, &Value)
@@ -253,10 +260,19 @@ func (repository *InitRepo) GetListtComments(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 		return
 	}
+	Value, err := repository.decorateComments(Parameter.Task_ID, Value)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var data interface{} = Value
+	if Parameter.Threaded {
+		data = threadComments(Value)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
 		"error": false,
-		"data":  Value,
+		"data":  data,
 	})
 }
 
@@ -329,6 +345,16 @@ func (repository *InitRepo) GetListData(c *gin.Context) {
 			Output = []models.ListDataAssignTo{{}}
 		}
 	}
+	Output, err := repository.attachContributedTasks(Output, Parameter.Userid)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := repository.attachSlaState(Output); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	renderTaskDescriptions(Output)
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
@@ -365,6 +391,7 @@ func (repository *InitRepo) GetHeaderListData(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 		return
 	}
+	renderTaskDescriptions(Output)
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
 		"error": false,
@@ -382,8 +409,7 @@ func (repository *InitRepo) GetIncomingTask(c *gin.Context) {
 		return
 	}
 
-	var Output interface{}
-	Output = make([]models.ListIncomingTask, 0)
+	Output := make([]models.ListIncomingTask, 0)
 	models.GenerateValue_GetIncomingTask(Parameter.Userid)
 	helper.MasterQuery = models.QueryGetListIncomingTask
 	errs := helper.MasterExec_Get(repository.DbPg, &Output)
@@ -391,6 +417,23 @@ func (repository *InitRepo) GetIncomingTask(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 		return
 	}
+	codes := make([]string, len(Output))
+	for i := range Output {
+		codes[i] = Output[i].TaskCode
+	}
+	var states []models.SchedulerTaskState
+	if err := repository.DbPg.Where("task_code IN ? AND paused", codes).Find(&states).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	paused := make(map[string]bool, len(states))
+	for _, state := range states {
+		paused[state.TaskCode] = true
+	}
+	for i := range Output {
+		Output[i].GenerateEveryText = helper.DescribeRecurrence(Output[i].GenerateEvery)
+		Output[i].Paused = paused[Output[i].TaskCode]
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
 		"error": false,
@@ -481,11 +524,24 @@ func readPdf(path string) (string, error) {
 // @Router /Tasklist/InsertingComment [post]
 func (repository *InitRepo) InsertingComment(c *gin.Context) {
 	var AddingValue models.InsertComments
-	var Try []models.CategoryList
 	if err := c.ShouldBindJSON(&AddingValue); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": ""})
 		return
 	}
+	var threadRoot string
+	if AddingValue.Parent_ID != "" {
+		root, err := repository.threadRoot(AddingValue.Task_ID, AddingValue.Parent_ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		threadRoot = root
+	}
+	mentions := repository.resolveMentions(AddingValue.Comments)
+	if mentions == nil {
+		mentions = []models.TaskCommentMention{}
+	}
+	AddingValue.Tagging_User = mentionedOfficers(AddingValue.Tagging_User, mentions, AddingValue.Emp_ID)
 	if AddingValue.Comments == "TESTING" {
 		content, err := readPdf(AddingValue.File_Path) // Read local pdf file
 		if err != nil {
@@ -495,49 +551,44 @@ func (repository *InitRepo) InsertingComment(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "Content": content})
 	} else {
 
+		var commentID string
 		if AddingValue.File_Path == "" || len(AddingValue.File_Path) < 1 {
 			AddingValue.File_Path = ""
-			helper.MasterQuery = models.Query_InsertingComments + "('" + AddingValue.Task_ID + "','" + AddingValue.Comments + "','" + AddingValue.Emp_ID + "','" + AddingValue.File_Path + "','" + AddingValue.Content_Name + "','')"
-			errs := helper.MasterExec_Get(repository.DbPg, &Try)
-			if errs != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
+			var err error
+			commentID, err = repository.insertComment(AddingValue, AddingValue.File_Path, AddingValue.Content_Name)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded"})
+			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "mentions": mentions})
 
 		} else {
-			ObjID, err := helper.InsertPDFToMongoDB(AddingValue.File_Path)
+			stored, err := helper.PutFile(c.Request.Context(), repository.Blobs, AddingValue.File_Path, "", "")
 			if err != nil {
-				log.Printf("Failed to insert PDF into MongoDB: %v", err)
+				log.Printf("Failed to store PDF: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload the PDF"})
 				return
 			}
-			ObjectID := ObjID
 			content, err := readPdf(AddingValue.File_Path) // Read local pdf file
 			if err != nil {
 				log.Printf("Failed to insert PDF into MongoDB: %v", err)
 			}
 			fmt.Println(content)
-			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded"})
-			fmt.Println("PDF successfully inserted into MongoDB.")
-			helper.MasterQuery = ""
-			helper.MasterQuery = models.Query_InsertingComments + "('" + AddingValue.Task_ID + "','" + AddingValue.Comments + "','" + AddingValue.Emp_ID + "','" + ObjectID.Hex() + "','" + AddingValue.Content_Name + "')"
-			errs := helper.MasterExec_Get(repository.DbPg, &Try)
-			if errs != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
+			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "mentions": mentions})
+			fmt.Println("PDF successfully stored.")
+			commentID, err = repository.insertComment(AddingValue, stored.Key, AddingValue.Content_Name)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded"})
+			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "mentions": mentions})
 		}
 
-		for _, value := range AddingValue.Tagging_User {
-			helper.MasterQuery = models.Query_InsertingNotif + "('" + value + "','TaskList_Comments','" + AddingValue.Task_ID + "','" + AddingValue.Comments + "')"
-			errs := helper.MasterExec_Get(repository.DbPg, &Try)
-			if errs != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
-				return
+		repository.recordPostedComment(AddingValue, commentID, threadRoot, mentions)
+		for _, empNo := range AddingValue.Tagging_User {
+			if err := repository.insertNotif(empNo, "TaskList_Comments", AddingValue.Task_ID, AddingValue.Comments); err != nil {
+				log.Printf("Failed to notify %s of comment %s: %v", empNo, commentID, err)
 			}
-
 		}
 	}
 
@@ -575,20 +626,20 @@ func (repository *InitRepo) InsertingDocumentUpload(c *gin.Context) {
 	var fileObjectId string
 
 	if AddingValue.FilePath != "" {
-		ObjID, err := helper.InsertPDFToMongoDB(AddingValue.FilePath)
+		stored, err := helper.PutFile(c.Request.Context(), repository.Blobs, AddingValue.FilePath, "", "")
 		if err != nil {
-			log.Printf("Failed to insert PDF into MongoDB: %v", err)
+			log.Printf("Failed to store PDF: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload the PDF"})
 			return
 		}
-		fileObjectId = ObjID.Hex()
+		fileObjectId = stored.Key
 
 		content, err := readPdf(AddingValue.FilePath)
 		if err != nil {
 			log.Printf("Failed to read PDF: %v", err)
 		}
 		fmt.Println(content)
-		fmt.Println("PDF successfully inserted into MongoDB.")
+		fmt.Println("PDF successfully stored.")
 	}
 
 	helper.MasterQuery = fmt.Sprintf(
@@ -767,7 +818,7 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 			fmt.Println("Error converting Remainder_Date to integer:", err)
 			return
 		}
-		remainderDate := enddate.AddDate(0, 0, -remainderDays)
+		remainderDate := repository.reminderDate(AddingValue.Departemen, enddate, remainderDays)
 		remainder_date = remainderDate.Format("2006-01-02")
 		helper.MasterQuery = models.Query_InsertSubtask + "('" + AddingValue.Departemen + "', '" + AddingValue.Topic + "', '" + AddingValue.Assign_To + "', '" + AddingValue.Priority + "','" + AddingValue.Subject + "', '" + AddingValue.Task_Name + "', '" + AddingValue.Start_Date + "', '" + AddingValue.End_Date + "', '" + AddingValue.Addwho + "','" + remainder_date + "','" + AddingValue.Task_id_parent_of + "', '" + AddingValue.Task_type + "')"
 		errs := helper.MasterExec_Get(repository.DbPg, &AddingValue)
@@ -775,6 +826,7 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 			return
 		}
+		repository.distributeNewGroupTask(AddingValue.Assign_To, AddingValue.Addwho, AddingValue.Subject)
 
 	} else {
 		var remainder_date string
@@ -788,6 +840,15 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 			fmt.Println("Error converting Remainder_Date to integer:", err)
 			return
 		}
+		// New assignments follow an active out-of-office delegation.
+		delegation, errDelegation := repository.activeDelegation(AddingValue.Assign_To, time.Now())
+		if errDelegation != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errDelegation.Error()})
+			return
+		}
+		if delegation != nil {
+			AddingValue.Assign_To = delegation.DelegateTo
+		}
 		var username = ""
 		helper.MasterQuery = `select "emp_no","emp_name" from public."dynamic_group" where "emp_no" =` + " '" + AddingValue.Assign_To + "' "
 		errs_1 := helper.MasterExec_Get(repository.DbPg, &Userassignto)
@@ -817,7 +878,7 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 		username_reporter = UserReporter[0].Emp_Name
 		var CurentDate = time.Now().Format("2006-01-02:15:04")
 
-		remainderDate := enddate.AddDate(0, 0, -remainderDays)
+		remainderDate := repository.reminderDate(AddingValue.Departemen, enddate, remainderDays)
 		remainder_date = remainderDate.Format("2006-01-02")
 		helper.MasterQuery = models.Query_InsertSubtask + "('" + AddingValue.Departemen + "', '" + AddingValue.Topic + "', '" + AddingValue.Assign_To + "', '" + AddingValue.Priority + "','" + AddingValue.Subject + "', '" + AddingValue.Task_Name + "', '" + AddingValue.Start_Date + "', '" + AddingValue.End_Date + "', '" + AddingValue.Addwho + "','" + remainder_date + "','" + AddingValue.Task_id_parent_of + "', '" + AddingValue.Task_type + "')"
 		errs := helper.MasterExec_Get(repository.DbPg, &AddingValue)
@@ -839,6 +900,11 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 			"data":  Taskidftch,
 		})
 		Taskid = Taskidftch[0].Task_ID
+		if delegation != nil {
+			if err := repository.recordDelegation(*delegation, Taskid, AddingValue.Addwho); err != nil {
+				log.Printf("Out of office: task %s: %v", Taskid, err)
+			}
+		}
 
 		var SendMailto = ""
 		helper.MasterQuery = `Select Email from users where number_officer =` + "'" + AddingValue.Assign_To + "' "
@@ -869,7 +935,7 @@ func (repository *InitRepo) InsertingSubtask(c *gin.Context) {
 			"param4":         AddingValue.Remainder_Date,
 			"param5":         username_reporter,
 			"param6":         clickdbtn,
-			"param7":         "",
+			"param7":         helper.RenderMarkdown(repository.taskDescription(Taskid)),
 			"param8":         "",
 			"param9":         "",
 			"param10":        "",
@@ -939,7 +1005,7 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 			fmt.Println("Error converting Remainder_Date to integer:", err)
 			return
 		}
-		remainderDate := enddate.AddDate(0, 0, -remainderDays)
+		remainderDate := repository.reminderDate(AddingValue.Departemen, enddate, remainderDays)
 		remainder_date = remainderDate.Format("2006-01-02")
 		helper.MasterQuery = models.Query_InsertTaskManual + "('" + AddingValue.Departemen + "', '" + AddingValue.Topic + "', '" + AddingValue.Assign_To + "', '" + AddingValue.Priority + "','" + AddingValue.Subject + "', '" + AddingValue.Task_Name + "', '" + AddingValue.Start_Date + "', '" + AddingValue.End_Date + "', '" + AddingValue.Addwho + "','" + remainder_date + "' , '" + AddingValue.Task_type + "')"
 		errs := helper.MasterExec_Get(repository.DbPg, &AddingValue)
@@ -947,6 +1013,7 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 			return
 		}
+		repository.distributeNewGroupTask(AddingValue.Assign_To, AddingValue.Addwho, AddingValue.Subject)
 
 	} else {
 		var remainder_date string
@@ -960,6 +1027,15 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 			fmt.Println("Error converting Remainder_Date to integer:", err)
 			return
 		}
+		// New assignments follow an active out-of-office delegation.
+		delegation, errDelegation := repository.activeDelegation(AddingValue.Assign_To, time.Now())
+		if errDelegation != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errDelegation.Error()})
+			return
+		}
+		if delegation != nil {
+			AddingValue.Assign_To = delegation.DelegateTo
+		}
 		var username = ""
 		helper.MasterQuery = `select "emp_no","emp_name" from public."dynamic_group" where "emp_no" =` + " '" + AddingValue.Assign_To + "' "
 		errs_1 := helper.MasterExec_Get(repository.DbPg, &Userassignto)
@@ -989,7 +1065,7 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 		username_reporter = UserReporter[0].Emp_Name
 		var CurentDate = time.Now().Format("2006-01-02:15:04")
 
-		remainderDate := enddate.AddDate(0, 0, -remainderDays)
+		remainderDate := repository.reminderDate(AddingValue.Departemen, enddate, remainderDays)
 		remainder_date = remainderDate.Format("2006-01-02")
 		helper.MasterQuery = models.Query_InsertTaskManual + "('" + AddingValue.Departemen + "', '" + AddingValue.Topic + "', '" + AddingValue.Assign_To + "', '" + AddingValue.Priority + "','" + AddingValue.Subject + "', '" + AddingValue.Task_Name + "', '" + AddingValue.Start_Date + "', '" + AddingValue.End_Date + "', '" + AddingValue.Addwho + "','" + remainder_date + "', '" + AddingValue.Task_type + "')"
 		errs := helper.MasterExec_Get(repository.DbPg, &AddingValue)
@@ -1011,6 +1087,11 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 			"data":  Taskidftch,
 		})
 		Taskid = Taskidftch[0].Task_ID
+		if delegation != nil {
+			if err := repository.recordDelegation(*delegation, Taskid, AddingValue.Addwho); err != nil {
+				log.Printf("Out of office: task %s: %v", Taskid, err)
+			}
+		}
 
 		var SendMailto = ""
 		helper.MasterQuery = `Select Email from users where number_officer =` + "'" + AddingValue.Assign_To + "' "
@@ -1041,7 +1122,7 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 			"param4":         AddingValue.Remainder_Date,
 			"param5":         username_reporter,
 			"param6":         clickdbtn,
-			"param7":         "",
+			"param7":         helper.RenderMarkdown(repository.taskDescription(Taskid)),
 			"param8":         "",
 			"param9":         "",
 			"param10":        "",
@@ -1079,7 +1160,7 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 }
 
 // @Summary Upload a file
-// @Description Upload a file to the specified bucket using the file path and file name.
+// @Description Upload a file to the blob store under the file name, reading it from the file path.
 // @Accept json
 // @Produce json
 // @Param file body models.FileUpload true "File Upload Info"
@@ -1088,7 +1169,6 @@ func (repository *InitRepo) InsertingTaskManual(c *gin.Context) {
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /Tasklist/UploadFile [post]
 func (repository *InitRepo) UploadingFile(c *gin.Context) {
-	bucketName := helper.GodotEnv("BucketName")
 	var fileUpload models.FileUpload
 	if err := c.ShouldBindJSON(&fileUpload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "FilePath and FileName are required"})
@@ -1096,13 +1176,13 @@ func (repository *InitRepo) UploadingFile(c *gin.Context) {
 	}
 	FileName := fileUpload.FileName
 	FilePath := fileUpload.FilePath
-	err := helper.UploadFile(bucketName, FilePath, FileName)
+	stored, err := helper.PutFile(c.Request.Context(), repository.Blobs, FilePath, helper.NewBlobKey(), FileName)
 	if err != nil {
 		fmt.Println("Error uploading file:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded"})
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "object_id": stored.Key})
 }
 
 // @Summary Upload a file
@@ -1123,60 +1203,14 @@ func (repository *InitRepo) UploadingToMongoDB_V1(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "FilePath is required"})
 		return
 	}
-	ObjID, err := helper.InsertPDFToMongoDB(fileUpload.FilePath)
+	stored, err := helper.PutFile(c.Request.Context(), repository.Blobs, fileUpload.FilePath, "", "")
 	if err != nil {
-		log.Printf("Failed to insert PDF into MongoDB: %v", err)
+		log.Printf("Failed to store PDF: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload the PDF"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded ,And This Your ID :" + ObjID.String()})
-	fmt.Println("PDF successfully inserted into MongoDB.")
-
-}
-
-// @Summary Upload a file
-// @Accept multipart/form-data
-// @Produce json
-// @Param file formData file true "File to upload"
-// @Success 200 {object} map[string]string "Successfully uploaded"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 500 {object} map[string]string "Internal server error"
-// @Router /Tasklist/UploadingToMongoDB [post]
-func (repository *InitRepo) UploadingToMongoDB(c *gin.Context) {
-	// Parse the multipart form, with a maximum memory limit.
-	err := c.Request.ParseMultipartForm(10 << 20) // 10 MB limit
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
-		return
-	}
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
-		return
-	}
-	homeDir := os.TempDir()
-
-	testingFolderPath := filepath.Join(homeDir, "DocumentTempSipam")
-
-	tempFilePath := testingFolderPath + "\\" + file.Filename // Define a temporary path
-	if err := c.SaveUploadedFile(file, tempFilePath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save the file"})
-		return
-	}
-	c.JSON(http.StatusOK, gin.H{"message": tempFilePath})
-
-	// ObjID, err := helper.InsertPDFToMongoDB(tempFilePath)
-	// if err != nil {
-	// 	log.Printf("Failed to insert PDF into MongoDB: %v", err)
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload the PDF"})
-	// 	return
-	// }
-
-	// c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded, and this is your ID: " + ObjID.String()})
-	// fmt.Println("PDF successfully inserted into MongoDB.")
-
-	// // Optionally: clean up the temporary file after upload
-	// os.Remove(tempFilePath)
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded ,And This Your ID :" + stored.Key})
+	fmt.Println("PDF successfully stored.")
 
 }
 
@@ -1209,13 +1243,7 @@ func (repository *InitRepo) DownloadingToMongoDB(c *gin.Context) {
 	// 	}
 	// }
 	// outputFilePath := filepath.Join(testingFolderPath, Parameter.FileName)
-	var objectID = Parameter.ObjectID
-	fileID, err := primitive.ObjectIDFromHex(objectID)
-	if err != nil {
-		fmt.Printf("Invalid ObjectID: %v\n", err)
-		return
-	}
-	base64Data, filesize, err := helper.DownloadFileFromMongoDB(fileID)
+	base64Data, filesize, err := repository.downloadOptimizedPDF(c.Request.Context(), Parameter.ObjectID)
 	if err != nil {
 		fmt.Printf("Error downloading file: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1253,6 +1281,28 @@ func (repository *InitRepo) UpdatingProgressTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": ""})
 		return
 	}
+	// With several assignees, DONE from a contributor only marks their part
+	// and DONE from anyone else is refused, so the caller must say who it is.
+	if normalizeProgress(AddingValue.ProgresValue) == models.TaskProgressDone {
+		var assignees int64
+		if err := repository.DbPg.Model(&models.TaskAssignee{}).Where("task_id = ?", AddingValue.Task_ID).Count(&assignees).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if assignees > 0 {
+			if AddingValue.Userid == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "userid is required to complete a task with several assignees"})
+				return
+			}
+			done, err := repository.markAssigneeCompletion(AddingValue.Task_ID, AddingValue.Userid, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "Successfully uploaded", "task_done": done})
+			return
+		}
+	}
 	models.GenerateValue_UpdateTask(AddingValue.Task_ID, AddingValue.ProgresValue)
 	helper.MasterQuery = models.QueryUpdateTask
 	errs := helper.MasterExec_Get(repository.DbPg, nil)
@@ -1280,6 +1330,12 @@ func (repository *InitRepo) GetNotifTaskList(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 		return
 	}
+	Output, err := repository.attachContributedTasks(Fetching, Parameter.UserID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	Fetching = Output.([]models.ColumnShowNotif)
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
 		"error": false,
@@ -1329,6 +1385,54 @@ func (repository *InitRepo) GetListUserAssignHistory(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": errs})
 		return
 	}
+	var escalations []models.EscalationEvent
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Param).Order("created_at").Find(&escalations).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, escalation := range escalations {
+		Fetching = append(Fetching, models.ColumnShowUserAssignHistory{
+			Assigner:       "SYSTEM",
+			Assigner_name:  "Escalation",
+			User_assign_to: escalation.EscalatedTo,
+			Emp_name:       escalation.EscalatedToName,
+			Start_date:     escalation.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			Duration:       fmt.Sprintf("%.1f working hours overdue", escalation.OverdueHours),
+			Status:         fmt.Sprintf("ESCALATED L%d", escalation.Level),
+		})
+	}
+	// User_Assign_History shows delegations as plain updates.
+	var delegations []models.TaskDelegation
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Param).Order("delegated_at").Find(&delegations).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, delegation := range delegations {
+		delegated := models.ColumnShowUserAssignHistory{
+			Assigner:       delegation.Owner,
+			Assigner_name:  repository.employeeName(delegation.Owner),
+			User_assign_to: delegation.Delegate,
+			Emp_name:       repository.employeeName(delegation.Delegate),
+			Start_date:     delegation.DelegatedAt.Format("2006-01-02T15:04:05Z"),
+			Duration:       "Out of office",
+			Status:         "DELEGATED",
+		}
+		if delegation.ReturnedAt != nil {
+			delegated.End_date = delegation.ReturnedAt.Format("2006-01-02T15:04:05Z")
+		}
+		Fetching = append(Fetching, delegated)
+		if delegation.ReturnedAt != nil {
+			Fetching = append(Fetching, models.ColumnShowUserAssignHistory{
+				Assigner:       delegation.Delegate,
+				Assigner_name:  delegated.Emp_name,
+				User_assign_to: delegation.Owner,
+				Emp_name:       delegated.Assigner_name,
+				Start_date:     delegated.End_date,
+				Duration:       "Back from out of office",
+				Status:         "DELEGATION RETURNED",
+			})
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"code":  200,
 		"error": false,
@@ -1363,6 +1467,7 @@ func (repository *InitRepo) UpdateStatusClickednotif(c *gin.Context) {
 	})
 }
 
+// @Description Moving a task someone already holds is limited to their direct supervisor and admins; others go through RequestingReassignment.
 // @Param file body models.InsertUpdategroupAssignTOModels true "Inserting Data"
 //
 //	@Router			/Tasklist/InsertUpdategroupAssignTO [Post]
@@ -1380,6 +1485,15 @@ func (repository *InitRepo) InsertUpdategroupAssignTO(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": ""})
 		return
 	}
+	allowed, err := repository.mayAssignDirectly(Parameter.P_task_id, Parameter.P_user_assign_to, Parameter.P_assigner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the assignee's supervisor can reassign this task; send a RequestingReassignment instead"})
+		return
+	}
 	helper.MasterQuery = models.Query_InsertUpdategroupAssignTO + "('" + Parameter.P_task_id + "','" + Parameter.P_user_assign_to + "','" + Parameter.P_group_assign + "','" + Parameter.P_assigner + "','" + Parameter.P_param + "')"
 	errs := helper.MasterExec_Get(repository.DbPg, "")
 	if errs != nil {
@@ -1473,7 +1587,7 @@ func (repository *InitRepo) InsertUpdategroupAssignTO(c *gin.Context) {
 		"param4":         "",
 		"param5":         username_reporter,
 		"param6":         clickdbtn,
-		"param7":         "",
+		"param7":         helper.RenderMarkdown(repository.taskDescription(Parameter.P_task_id)),
 		"param8":         "",
 		"param9":         "",
 		"param10":        "",
@@ -1519,6 +1633,10 @@ func (repository *InitRepo) InsertingSchedulerMasterTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": ""})
 		return
 	}
+	if _, err := helper.ParseRecurrence(Parameter.Generate_Every, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid generate_every: " + err.Error()})
+		return
+	}
 	helper.MasterQuery = models.Query_InsertSchedulerMasterTaskList + "('" + Parameter.Topic_Code + "', '" + Parameter.Subject + "', '" + Parameter.Dept + "', '" + Parameter.Task_Name + "', '" + Parameter.Task_category + "', '" + Parameter.Generate_Every + "', '" + Parameter.Priority + "', '" + Parameter.Estimated_Time_Done + "', '" + Parameter.Assign_To + "', '" + Parameter.Remainder_Date + "','" + Parameter.Creator + "','" + Parameter.Task_Type + "')"
 	errs := helper.MasterExec_Get(repository.DbPg, "")
 	if errs != nil {
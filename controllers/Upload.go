@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxUploadBytes = 100 << 20
+
+// storeUploadedPart streams one file of a multipart form into the blob
+// store. The content type is sniffed when the client did not send a useful
+// one.
+func (repository *InitRepo) storeUploadedPart(ctx context.Context, part *multipart.Part) (helper.BlobInfo, error) {
+	reader := bufio.NewReader(part)
+	contentType := part.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, _ := reader.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+	return repository.Blobs.Put(ctx, helper.BlobInfo{
+		Name:        filepath.Base(part.FileName()),
+		ContentType: contentType,
+	}, reader)
+}
+
+// downloadOptimizedPDF reads a whole PDF from the blob store and shrinks it,
+// for DownloadingToMongoDB.
+func (repository *InitRepo) downloadOptimizedPDF(ctx context.Context, key string) ([]byte, int, error) {
+	blob, err := repository.Blobs.Open(ctx, key)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not open %s: %w", key, err)
+	}
+	defer blob.Close()
+	content, err := io.ReadAll(blob)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read file content: %w", err)
+	}
+	compressed, err := helper.OptimizePDF(content)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not compress PDF: %w", err)
+	}
+	return compressed, len(compressed), nil
+}
+
+// UploadingToMongoDB godoc
+// @Summary Upload a file
+// @Description Streams the "file" part of a multipart form straight into the blob store, without a temp file on the API host, and returns the stored object ID, size, content type and SHA-256.
+// @Tags Upload
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Success 200 {object} helper.BlobInfo
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /Tasklist/UploadingToMongoDB [post]
+func (repository *InitRepo) UploadingToMongoDB(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		stored, err := repository.storeUploadedPart(c.Request.Context(), part)
+		part.Close()
+		if err != nil {
+			log.Printf("Failed to store upload %s: %v", part.FileName(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload the file"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code":  200,
+			"error": false,
+			"data":  stored,
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+}
+
+// DownloadingFile godoc
+// @Summary Download a file
+// @Description Streams the original bytes with Content-Type, Content-Disposition, Content-Length and ETag, and honours Range requests so browsers can preview and resume large files. With optimize=true a PDF is shrunk first; that copy is built in memory.
+// @Tags Upload
+// @Produce octet-stream
+// @Param objectid query string true "Object ID"
+// @Param download query bool false "Save as a file instead of showing it inline"
+// @Param optimize query bool false "Shrink PDFs before sending them"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 400 {object} map[string]string "Invalid input"
+// @Failure 404 {object} map[string]string "Not found"
+// @Router /Tasklist/DownloadingFile [get]
+func (repository *InitRepo) DownloadingFile(c *gin.Context) {
+	var Parameter models.ParamDownloadingFile
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	blob, err := repository.Blobs.Open(c.Request.Context(), Parameter.ObjectID)
+	if errors.Is(err, helper.ErrBlobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer blob.Close()
+	file := blob.Info()
+
+	etag := file.SHA256
+	if etag == "" {
+		etag = file.Key + "-" + strconv.FormatInt(file.Size, 10)
+	}
+	var content io.ReadSeeker = blob
+	if Parameter.Optimize && file.ContentType == "application/pdf" {
+		original, err := io.ReadAll(blob)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		content = bytes.NewReader(original)
+		if optimized, err := helper.OptimizePDF(original); err != nil {
+			log.Printf("Failed to optimize %s, sending the original: %v", file.Key, err)
+		} else {
+			content = bytes.NewReader(optimized)
+			etag += "-optimized"
+		}
+	}
+
+	disposition := "inline"
+	if Parameter.Download {
+		disposition = "attachment"
+	}
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": file.Name}); header != "" {
+		disposition = header
+	}
+	c.Header("Content-Disposition", disposition)
+	if file.ContentType != "" {
+		c.Header("Content-Type", file.ContentType)
+	}
+	c.Header("ETag", `"`+etag+`"`)
+	http.ServeContent(c.Writer, c.Request, file.Name, file.UploadedAt, content)
+}
@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	helper "go-todolist/helpers"
+	"go-todolist/models"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// candidateAssignees lists the members of a group, or the people the given
+// reporter can assign to (GetDataAssignTo) when no group is given.
+func (repository *InitRepo) candidateAssignees(userid, group string) ([]models.ListDataAssignTo, error) {
+	var candidates []models.ListDataAssignTo
+	if group != "" {
+		err := repository.DbPg.Raw(models.QueryGetGroupMembers, group).Scan(&candidates).Error
+		return candidates, err
+	}
+	query := "Select * from public.SP_New_Version_TaskList_Universal(?, ?, '') AS " + models.RetrunTableAssignTo
+	err := repository.DbPg.Raw(query, "GetDataAssignTo", userid).Scan(&candidates).Error
+	return candidates, err
+}
+
+// departmentAssignees lists the people who worked tasks of a department
+// lately.
+func (repository *InitRepo) departmentAssignees(departemen string) ([]models.ListDataAssignTo, error) {
+	var candidates []models.ListDataAssignTo
+	err := repository.DbPg.Raw(models.QueryGetDepartmentAssignees, departemen).Scan(&candidates).Error
+	return candidates, err
+}
+
+// keepDepartmentAssignees keeps the candidates who worked tasks of a
+// department lately.
+func (repository *InitRepo) keepDepartmentAssignees(candidates []models.ListDataAssignTo, departemen string) ([]models.ListDataAssignTo, error) {
+	inDepartment, err := repository.departmentAssignees(departemen)
+	if err != nil {
+		return nil, err
+	}
+	works := make(map[string]bool, len(inDepartment))
+	for _, candidate := range inDepartment {
+		works[candidate.Emp_No] = true
+	}
+	kept := candidates[:0]
+	for _, candidate := range candidates {
+		if works[candidate.Emp_No] {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept, nil
+}
+
+// outOfOfficeAt returns the out-of-office period covering at of each of
+// the employees who are away, keyed by employee number.
+func (repository *InitRepo) outOfOfficeAt(empNos []string, at time.Time) (map[string]models.UserOutOfOffice, error) {
+	var periods []models.UserOutOfOffice
+	err := repository.DbPg.Where("emp_no IN ? AND start_date <= ? AND end_date >= ?", empNos, at, at).
+		Order("end_date").Find(&periods).Error
+	if err != nil {
+		return nil, err
+	}
+	away := make(map[string]models.UserOutOfOffice, len(periods))
+	for _, period := range periods {
+		// Ordered by end date, so the period ending last wins.
+		away[period.EmpNo] = period
+	}
+	return away, nil
+}
+
+// GetAssigneeSuggestions godoc
+// @Summary Rank candidate assignees by workload
+// @Description Ranks the members of a group, or the people a reporter can assign to, from least to most busy. A department narrows either list to the people who worked its tasks in the last 180 days, and on its own ranks all of them. Employees who are out of office are listed last.
+// @Tags Workload
+// @Produce json
+// @Param userid query string false "Reporter employee number (GetDataAssignTo candidates)"
+// @Param group query string false "Group name (dynamic_group members)"
+// @Param departemen query string false "Department (people assigned its tasks in the last 180 days)"
+// @Success 200 {object} models.ValueAssigneeSuggestion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /Tasklist/GetAssigneeSuggestions [get]
+func (repository *InitRepo) GetAssigneeSuggestions(c *gin.Context) {
+	var Parameter models.ParamAssigneeSuggestion
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if Parameter.Userid == "" && Parameter.Group == "" && Parameter.Departemen == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "userid, group or departemen is required"})
+		return
+	}
+	var candidates []models.ListDataAssignTo
+	var err error
+	if Parameter.Userid == "" && Parameter.Group == "" {
+		candidates, err = repository.departmentAssignees(Parameter.Departemen)
+	} else if candidates, err = repository.candidateAssignees(Parameter.Userid, Parameter.Group); err == nil && Parameter.Departemen != "" {
+		candidates, err = repository.keepDepartmentAssignees(candidates, Parameter.Departemen)
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	Value, err := repository.suggestAssignees(candidates, time.Now())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sort.SliceStable(Value, func(i, j int) bool {
+		if Value[i].Available != Value[j].Available {
+			return Value[i].Available
+		}
+		return Value[i].Score < Value[j].Score
+	})
+	for i := range Value {
+		Value[i].Rank = i + 1
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// suggestAssignees scores the workload of each candidate, in the order
+// given. Summaries, open tasks, effort and absences are read with one query
+// each for all candidates.
+func (repository *InitRepo) suggestAssignees(candidates []models.ListDataAssignTo, now time.Time) ([]models.ValueAssigneeSuggestion, error) {
+	suggestions := make([]models.ValueAssigneeSuggestion, 0, len(candidates))
+	if len(candidates) == 0 {
+		return suggestions, nil
+	}
+	empNos := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		empNos = append(empNos, candidate.Emp_No)
+	}
+
+	var summaries []models.WorkloadSummary
+	if err := repository.DbPg.Raw(models.QueryGetTaskSummaries, empNos).Scan(&summaries).Error; err != nil {
+		return nil, err
+	}
+	summaryOf := make(map[string]models.ListDataSummary, len(summaries))
+	for _, summary := range summaries {
+		if _, seen := summaryOf[summary.Candidate]; !seen {
+			summaryOf[summary.Candidate] = summary.ListDataSummary
+		}
+	}
+	var tasks []models.WorkloadAssigneeTask
+	if err := repository.DbPg.Raw(models.QueryGetOpenTaskByAssignees, empNos).Scan(&tasks).Error; err != nil {
+		return nil, err
+	}
+	tasksOf := make(map[string][]helper.WorkloadTask, len(candidates))
+	for _, task := range tasks {
+		tasksOf[task.User_Assign_To] = append(tasksOf[task.User_Assign_To], helper.WorkloadTask{Priority: task.Priority, Due: task.Estimated_Time_Done})
+	}
+	var efforts []models.WorkloadEffort
+	err := repository.DbPg.Model(&models.TaskEffortLog{}).
+		Where("emp_no IN ? AND logged_at >= ?", empNos, now.AddDate(0, 0, -7)).
+		Select("emp_no, SUM(minutes) AS minutes").Group("emp_no").Scan(&efforts).Error
+	if err != nil {
+		return nil, err
+	}
+	effortOf := make(map[string]int64, len(efforts))
+	for _, effort := range efforts {
+		effortOf[effort.Emp_No] = effort.Minutes
+	}
+	away, err := repository.outOfOfficeAt(empNos, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		summary := summaryOf[candidate.Emp_No]
+		suggestion := models.ValueAssigneeSuggestion{Emp_No: candidate.Emp_No, Emp_Name: candidate.Emp_Name, Available: true, Summary: summary}
+		input := helper.WorkloadInput{
+			New:         summary.NEW,
+			Open:        summary.OPEN,
+			InProgress:  summary.IN_PROGRESS,
+			Hold:        summary.HOLD,
+			Warning:     summary.WARNING,
+			Outdate:     summary.OUTDATE,
+			EffortHours: float64(effortOf[candidate.Emp_No]) / 60,
+			Tasks:       tasksOf[candidate.Emp_No],
+		}
+		if period, ok := away[candidate.Emp_No]; ok {
+			suggestion.Available = false
+			input.OutOfOfficeUntil = &period.EndDate
+		}
+		suggestion.Score, suggestion.Explanation = helper.ScoreWorkload(input, now)
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// InsertingEffortLog godoc
+// @Summary Log time spent on a task
+// @Tags Workload
+// @Accept json
+// @Produce json
+// @Param file body models.TaskEffortLog true "Effort"
+// @Success 200 {object} models.TaskEffortLog
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/InsertingEffortLog [post]
+func (repository *InitRepo) InsertingEffortLog(c *gin.Context) {
+	var Parameter models.TaskEffortLog
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if Parameter.Minutes <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minutes must be positive"})
+		return
+	}
+	Parameter.ID = 0
+	if Parameter.LoggedAt.IsZero() {
+		Parameter.LoggedAt = time.Now()
+	}
+	if err := repository.DbPg.Create(&Parameter).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Parameter,
+	})
+}
+
+// GetEffortLog godoc
+// @Summary List the effort logged on a task
+// @Tags Workload
+// @Produce json
+// @Param task_id query string true "Task ID"
+// @Success 200 {object} models.TaskEffortLog
+// @Router /Tasklist/GetEffortLog [get]
+func (repository *InitRepo) GetEffortLog(c *gin.Context) {
+	var Parameter models.ParamSlaTask
+	if err := c.ShouldBindQuery(&Parameter); err != nil || Parameter.Task_ID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "task_id is required"})
+		return
+	}
+	var Value []models.TaskEffortLog
+	if err := repository.DbPg.Where("task_id = ?", Parameter.Task_ID).Order("logged_at").Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// InsertingOutOfOffice godoc
+// @Summary Register an out-of-office period
+// @Description With delegate_to set, new assignments and notifications go to the delegate during the period. hand_over_open_tasks also moves the open tasks, which return to the owner when the period ends.
+// @Tags Workload
+// @Accept json
+// @Produce json
+// @Param file body models.ParamInsertOutOfOffice true "Out-of-office period"
+// @Success 200 {object} models.UserOutOfOffice
+// @Failure 400 {object} map[string]interface{}
+// @Router /Tasklist/InsertingOutOfOffice [post]
+func (repository *InitRepo) InsertingOutOfOffice(c *gin.Context) {
+	var Parameter models.ParamInsertOutOfOffice
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start, errStart := time.ParseInLocation("2006-01-02", Parameter.Start_Date, time.Local)
+	end, errEnd := time.ParseInLocation("2006-01-02", Parameter.End_Date, time.Local)
+	if errStart != nil || errEnd != nil || end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date must be YYYY-MM-DD with start_date on or before end_date"})
+		return
+	}
+	if Parameter.Delegate_To == Parameter.Emp_No {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delegate_to must be someone else"})
+		return
+	}
+	if Parameter.Delegate_To != "" {
+		var found []models.FetchUsernameAssign
+		err := repository.DbPg.Raw(`select "emp_no","emp_name" from public."dynamic_group" where "emp_no" = ? limit 1`, Parameter.Delegate_To).Scan(&found).Error
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(found) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown delegate " + Parameter.Delegate_To})
+			return
+		}
+	}
+	period := models.UserOutOfOffice{
+		EmpNo:     Parameter.Emp_No,
+		StartDate: start,
+		// The whole end date is out of office.
+		EndDate:           end.Add(24*time.Hour - time.Second),
+		Reason:            Parameter.Reason,
+		DelegateTo:        Parameter.Delegate_To,
+		HandOverOpenTasks: Parameter.Hand_Over_Open_Tasks && Parameter.Delegate_To != "",
+	}
+	if err := repository.DbPg.Create(&period).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Periods starting today hand their tasks over straight away.
+	if period.HandOverOpenTasks && !period.StartDate.After(time.Now()) {
+		if err := repository.handOverOpenTasks(period, time.Now()); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  period,
+	})
+}
+
+// GetOutOfOffice godoc
+// @Summary List out-of-office periods
+// @Tags Workload
+// @Produce json
+// @Param emp_no query string false "Employee number"
+// @Success 200 {object} models.UserOutOfOffice
+// @Router /Tasklist/GetOutOfOffice [get]
+func (repository *InitRepo) GetOutOfOffice(c *gin.Context) {
+	var Parameter models.ParamOutOfOffice
+	if err := c.ShouldBindQuery(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var Value []models.UserOutOfOffice
+	query := repository.DbPg.Where("end_date >= ?", time.Now()).Order("start_date")
+	if Parameter.Emp_No != "" {
+		query = query.Where("emp_no = ?", Parameter.Emp_No)
+	}
+	if err := query.Find(&Value).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  Value,
+	})
+}
+
+// DeletingOutOfOffice godoc
+// @Summary Delete an out-of-office period
+// @Description Delegated tasks still open are returned to the owner.
+// @Tags Workload
+// @Accept json
+// @Produce json
+// @Param file body models.ParamOutOfOfficeID true "Period ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /Tasklist/DeletingOutOfOffice [post]
+func (repository *InitRepo) DeletingOutOfOffice(c *gin.Context) {
+	var Parameter models.ParamOutOfOfficeID
+	if err := c.ShouldBindJSON(&Parameter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Ending a period early gives the delegated tasks back first.
+	var period models.UserOutOfOffice
+	if err := repository.DbPg.First(&period, Parameter.ID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if period.DelegateTo != "" && period.ReturnedAt == nil {
+		if err := repository.returnDelegatedTasks(period, time.Now()); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := repository.DbPg.Delete(&models.UserOutOfOffice{}, Parameter.ID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":  200,
+		"error": false,
+		"data":  "Success",
+	})
+}
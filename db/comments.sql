@@ -0,0 +1,27 @@
+-- Routine behind posting comments. It calls SP_InsertingComments and reads
+-- public.task_comments, the table the procedure writes. Apply with psql
+-- after deploying; every statement can be re-run.
+
+-- Posts a comment through SP_InsertingComments and returns its ID. The
+-- procedure makes the ID up and returns nothing, so the comment is the row
+-- this transaction inserted rather than the author's latest, which another
+-- request may have posted meanwhile.
+CREATE OR REPLACE FUNCTION public."inserting_comment_returning_id"(
+	p_task_id varchar, p_comments text, p_emp_id varchar,
+	p_file_path varchar, p_content_name varchar, p_tagging varchar)
+RETURNS varchar
+LANGUAGE plpgsql AS $$
+DECLARE
+	new_comment_id varchar;
+BEGIN
+	CALL public."SP_InsertingComments"(p_task_id, p_comments, p_emp_id,
+		p_file_path, p_content_name, p_tagging);
+	SELECT "comment_id" INTO new_comment_id FROM public."task_comments"
+	WHERE "task_id" = p_task_id
+		AND "xmin"::text = (txid_current() % 4294967296)::text;
+	IF new_comment_id IS NULL THEN
+		RAISE EXCEPTION 'SP_InsertingComments created no comment on task %', p_task_id;
+	END IF;
+	RETURN new_comment_id;
+END;
+$$;
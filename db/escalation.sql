@@ -0,0 +1,28 @@
+-- Routines behind the escalation runner. They read and update
+-- public.task_detail, the table SP_New_Version_TaskList_Universal builds its
+-- task views from. Apply with psql after deploying; every statement can be
+-- re-run.
+
+-- Unfinished tasks past their estimated time done.
+CREATE OR REPLACE FUNCTION public."get_overdue_task"()
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT "task_id"::varchar, "departemen"::varchar, "priority"::varchar,
+		"topic"::varchar, "subject"::varchar, "user_assign_to"::varchar,
+		"estimated_time_done"::timestamp
+	FROM public."task_detail"
+	WHERE "task_progress" NOT IN ('DONE', 'CLOSE')
+		AND "estimated_time_done" < now()
+		AND coalesce("user_assign_to", '') <> ''
+	ORDER BY "estimated_time_done";
+$$;
+
+CREATE OR REPLACE PROCEDURE public."SP_Update_TaskPriority"(p_task_id varchar, p_priority varchar)
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE public."task_detail" SET "priority" = p_priority WHERE "task_id" = p_task_id;
+	IF NOT FOUND THEN
+		RAISE EXCEPTION 'task % not found', p_task_id;
+	END IF;
+END;
+$$;
@@ -0,0 +1,103 @@
+-- Routines behind the scheduler endpoints. They work on the definitions
+-- Sp_InsertingSchedulerTask writes to public.scheduler_master_task and on
+-- scheduler_task_state, which the API migrates itself. Apply with psql after
+-- deploying; every statement can be re-run. The API's runner is the only
+-- thing meant to call generate_scheduler_task, so any database job that
+-- generated scheduler tasks on its own should be dropped.
+
+CREATE OR REPLACE PROCEDURE public."Sp_UpdatingSchedulerTask"(
+	p_task_code varchar, p_topic_code varchar, p_subject varchar, p_dept varchar,
+	p_task_name varchar, p_task_category varchar, p_generate_every varchar,
+	p_priority varchar, p_estimated_time_done varchar, p_assign_to varchar,
+	p_remainder_date varchar, p_creator varchar, p_task_type varchar)
+LANGUAGE plpgsql AS $$
+BEGIN
+	UPDATE public."scheduler_master_task" SET
+		"topic_code" = p_topic_code,
+		"subject" = p_subject,
+		"departemen" = p_dept,
+		"task_name" = p_task_name,
+		"task_category" = p_task_category,
+		"generate_every" = p_generate_every,
+		"priority" = p_priority,
+		"estimated_time_done" = p_estimated_time_done,
+		"assign_to" = p_assign_to,
+		"reminder_task" = p_remainder_date,
+		"creator" = p_creator,
+		"task_type" = p_task_type
+	WHERE "task_code" = p_task_code;
+	IF NOT FOUND THEN
+		RAISE EXCEPTION 'scheduler task % not found', p_task_code;
+	END IF;
+END;
+$$;
+
+-- Generated tasks and the run log are kept; only the definition goes.
+CREATE OR REPLACE PROCEDURE public."Sp_DeletingSchedulerTask"(p_task_code varchar, p_userid varchar)
+LANGUAGE plpgsql AS $$
+BEGIN
+	DELETE FROM public."scheduler_master_task" WHERE "task_code" = p_task_code;
+	IF NOT FOUND THEN
+		RAISE EXCEPTION 'scheduler task % not found', p_task_code;
+	END IF;
+	RAISE LOG 'scheduler task % deleted by %', p_task_code, p_userid;
+END;
+$$;
+
+-- Every definition, in the shape of getting_incoming_scheduler_task, for the
+-- API's runner to evaluate.
+CREATE OR REPLACE FUNCTION public."getting_scheduler_master_task"()
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT "task_code"::varchar, "departemen"::varchar, "subject"::varchar,
+		"task_name"::varchar, "task_category"::varchar, "generate_every"::varchar,
+		"priority"::varchar, "estimated_time_done"::varchar, "reminder_task"::varchar,
+		"assign_to"::varchar, "running_at"::timestamp, "next_running_at"::timestamp
+	FROM public."scheduler_master_task"
+	ORDER BY "task_code";
+$$;
+
+-- Creates one task from a definition through SP_InsertingNewManualTask and
+-- returns its ID. Paused definitions generate nothing, whoever calls this.
+-- estimated_time_done holds the days the task is given; anything else gives
+-- it one day.
+CREATE OR REPLACE FUNCTION public."generate_scheduler_task"(p_task_code varchar)
+RETURNS SETOF record
+LANGUAGE plpgsql AS $$
+DECLARE
+	def public."scheduler_master_task"%ROWTYPE;
+	days integer := 1;
+	new_task_id varchar;
+BEGIN
+	IF EXISTS (SELECT 1 FROM public."scheduler_task_state"
+		WHERE "task_code" = p_task_code AND "paused") THEN
+		RETURN;
+	END IF;
+	SELECT * INTO def FROM public."scheduler_master_task" WHERE "task_code" = p_task_code;
+	IF NOT FOUND THEN
+		RAISE EXCEPTION 'scheduler task % not found', p_task_code;
+	END IF;
+	IF def."estimated_time_done"::text ~ '^\s*[0-9]+\s*$' THEN
+		days := greatest(def."estimated_time_done"::text::integer, 1);
+	END IF;
+
+	CALL public."SP_InsertingNewManualTask"(
+		def."departemen"::varchar, def."topic_code"::varchar, def."assign_to"::varchar,
+		def."priority"::varchar, def."subject"::varchar, def."task_name"::varchar,
+		to_char(now(), 'YYYY-MM-DD'), to_char(now() + make_interval(days => days), 'YYYY-MM-DD'),
+		def."creator"::varchar, coalesce(def."reminder_task"::varchar, ''), def."task_type"::varchar);
+
+	-- The procedure makes up the ID and returns nothing, so the task is the
+	-- header row this transaction inserted rather than the reporter's latest,
+	-- which another session may have created meanwhile.
+	SELECT "task_id" INTO new_task_id FROM public."task_header"
+	WHERE "reporter" = def."creator"
+		AND "xmin"::text = (txid_current() % 4294967296)::text;
+	IF new_task_id IS NULL THEN
+		RAISE EXCEPTION 'SP_InsertingNewManualTask created no task for %', p_task_code;
+	END IF;
+	UPDATE public."scheduler_master_task" SET "running_at" = now()
+	WHERE "task_code" = p_task_code;
+	RETURN QUERY SELECT new_task_id;
+END;
+$$;
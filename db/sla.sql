@@ -0,0 +1,17 @@
+-- Routine behind the SLA evaluator. It reads public.task_detail, the table
+-- SP_New_Version_TaskList_Universal builds its task views from. Apply with
+-- psql after deploying; every statement can be re-run.
+
+-- Tasks the evaluator still has to look at: everything not closed, and tasks
+-- finished within the last day so their final SLA status gets recorded.
+CREATE OR REPLACE FUNCTION public."get_open_task_sla"()
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT "task_id"::varchar, "departemen"::varchar, "priority"::varchar,
+		"topic"::varchar, "task_progress"::varchar, "created_at"::timestamp,
+		"progress_date"::timestamp, "finish_date"::timestamp
+	FROM public."task_detail"
+	WHERE "task_progress" IS DISTINCT FROM 'CLOSE'
+		AND ("finish_date" IS NULL OR "finish_date" >= now() - interval '1 day')
+	ORDER BY "task_id";
+$$;
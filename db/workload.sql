@@ -0,0 +1,44 @@
+-- Routines behind the workload view, out-of-office delegation and group
+-- distribution. They read public.task_detail, the table
+-- SP_New_Version_TaskList_Universal builds its task views from. Apply with
+-- psql after deploying; every statement can be re-run.
+
+-- Unfinished tasks currently assigned to an employee.
+CREATE OR REPLACE FUNCTION public."get_open_task_by_assignee"(p_emp_no varchar)
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT "task_id"::varchar, "priority"::varchar, "task_progress"::varchar,
+		"estimated_time_done"::timestamp
+	FROM public."task_detail"
+	WHERE "user_assign_to" = p_emp_no
+		AND "task_progress" NOT IN ('DONE', 'CLOSE')
+	ORDER BY "estimated_time_done";
+$$;
+
+-- Unfinished tasks of several employees, for ranking them in one query.
+CREATE OR REPLACE FUNCTION public."get_open_task_by_assignees"(p_emp_nos varchar[])
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT "user_assign_to"::varchar, "task_id"::varchar, "priority"::varchar,
+		"task_progress"::varchar, "estimated_time_done"::timestamp
+	FROM public."task_detail"
+	WHERE "user_assign_to" = ANY (p_emp_nos)
+		AND "task_progress" NOT IN ('DONE', 'CLOSE')
+	ORDER BY "user_assign_to", "estimated_time_done";
+$$;
+
+-- The people who worked tasks of a department in the last 180 days, the
+-- candidates for its next task. Names come from dynamic_group when known.
+CREATE OR REPLACE FUNCTION public."get_department_assignees"(p_departemen varchar)
+RETURNS SETOF record
+LANGUAGE sql STABLE AS $$
+	SELECT a."emp_no"::varchar,
+		coalesce((SELECT g."emp_name" FROM public."dynamic_group" g
+			WHERE g."emp_no" = a."emp_no" LIMIT 1), a."emp_no")::varchar
+	FROM (SELECT DISTINCT "user_assign_to" AS "emp_no"
+		FROM public."task_detail"
+		WHERE "departemen" = p_departemen
+			AND "created_at" >= now() - interval '180 days'
+			AND coalesce("user_assign_to", '') <> '') a
+	ORDER BY 2;
+$$;
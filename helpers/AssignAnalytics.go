@@ -0,0 +1,170 @@
+package helper
+
+import (
+	"sort"
+	"time"
+)
+
+// AssignmentSpan is one period a task spent with one assignee, as listed by
+// User_Assign_History. End is nil while the assignee still holds the task.
+type AssignmentSpan struct {
+	TaskID       string
+	Group        string
+	Assignee     string
+	AssigneeName string
+	Start        time.Time
+	End          *time.Time
+}
+
+// HolderStats is how long one person keeps tasks before they move on.
+type HolderStats struct {
+	EmpNo          string  `json:"emp_no"`
+	EmpName        string  `json:"emp_name"`
+	Assignments    int     `json:"assignments"`
+	HandedOff      int     `json:"handed_off"`
+	TotalHoldHours float64 `json:"total_hold_hours"`
+	AvgHoldHours   float64 `json:"avg_hold_hours"`
+	MaxHoldHours   float64 `json:"max_hold_hours"`
+}
+
+// TaskBounce counts how often one task changed hands. BounceBacks are
+// moves back to someone who already held it.
+type TaskBounce struct {
+	TaskID        string   `json:"task_id"`
+	Reassignments int      `json:"reassignments"`
+	BounceBacks   int      `json:"bounce_backs"`
+	Holders       []string `json:"holders"`
+}
+
+// GroupReassignments ranks groups by how often their tasks change hands.
+type GroupReassignments struct {
+	Group                string  `json:"group"`
+	Tasks                int     `json:"tasks"`
+	Reassignments        int     `json:"reassignments"`
+	ReassignmentsPerTask float64 `json:"reassignments_per_task"`
+}
+
+// AssignmentAnalytics aggregates assignment history.
+type AssignmentAnalytics struct {
+	Tasks              int                  `json:"tasks"`
+	TasksReassigned    int                  `json:"tasks_reassigned"`
+	TotalReassignments int                  `json:"total_reassignments"`
+	TotalBounceBacks   int                  `json:"total_bounce_backs"`
+	Holders            []HolderStats        `json:"holders"`
+	MostBounced        []TaskBounce         `json:"most_bounced"`
+	Groups             []GroupReassignments `json:"groups"`
+}
+
+// AnalyzeAssignments aggregates spans into hold times, bounce counts and
+// per-group reassignments. Open spans are counted up to now. At most
+// topTasks bounced tasks are listed.
+func AnalyzeAssignments(spans []AssignmentSpan, now time.Time, topTasks int) AssignmentAnalytics {
+	byTask := make(map[string][]AssignmentSpan)
+	var taskOrder []string
+	for _, span := range spans {
+		if _, ok := byTask[span.TaskID]; !ok {
+			taskOrder = append(taskOrder, span.TaskID)
+		}
+		byTask[span.TaskID] = append(byTask[span.TaskID], span)
+	}
+
+	holders := make(map[string]*HolderStats)
+	groups := make(map[string]*GroupReassignments)
+	var result AssignmentAnalytics
+	var bounces []TaskBounce
+	for _, taskID := range taskOrder {
+		taskSpans := byTask[taskID]
+		sort.SliceStable(taskSpans, func(i, j int) bool { return taskSpans[i].Start.Before(taskSpans[j].Start) })
+
+		bounce := TaskBounce{TaskID: taskID}
+		held := make(map[string]bool)
+		var previous string
+		for _, span := range taskSpans {
+			stats, ok := holders[span.Assignee]
+			if !ok {
+				stats = &HolderStats{EmpNo: span.Assignee, EmpName: span.AssigneeName}
+				holders[span.Assignee] = stats
+			}
+			end := now
+			if span.End != nil {
+				end = *span.End
+				stats.HandedOff++
+			}
+			hours := end.Sub(span.Start).Hours()
+			if hours < 0 {
+				hours = 0
+			}
+			stats.Assignments++
+			stats.TotalHoldHours += hours
+			if hours > stats.MaxHoldHours {
+				stats.MaxHoldHours = hours
+			}
+
+			if span.Assignee == previous {
+				continue
+			}
+			if previous != "" {
+				bounce.Reassignments++
+				if held[span.Assignee] {
+					bounce.BounceBacks++
+				}
+			}
+			held[span.Assignee] = true
+			bounce.Holders = append(bounce.Holders, span.Assignee)
+			previous = span.Assignee
+		}
+
+		result.Tasks++
+		result.TotalReassignments += bounce.Reassignments
+		result.TotalBounceBacks += bounce.BounceBacks
+		if bounce.Reassignments > 0 {
+			result.TasksReassigned++
+			bounces = append(bounces, bounce)
+		}
+		if group := taskSpans[0].Group; group != "" {
+			stats, ok := groups[group]
+			if !ok {
+				stats = &GroupReassignments{Group: group}
+				groups[group] = stats
+			}
+			stats.Tasks++
+			stats.Reassignments += bounce.Reassignments
+		}
+	}
+
+	for _, stats := range holders {
+		if stats.Assignments > 0 {
+			stats.AvgHoldHours = stats.TotalHoldHours / float64(stats.Assignments)
+		}
+		result.Holders = append(result.Holders, *stats)
+	}
+	sort.Slice(result.Holders, func(i, j int) bool {
+		if result.Holders[i].AvgHoldHours != result.Holders[j].AvgHoldHours {
+			return result.Holders[i].AvgHoldHours > result.Holders[j].AvgHoldHours
+		}
+		return result.Holders[i].EmpNo < result.Holders[j].EmpNo
+	})
+
+	sort.SliceStable(bounces, func(i, j int) bool {
+		if bounces[i].Reassignments != bounces[j].Reassignments {
+			return bounces[i].Reassignments > bounces[j].Reassignments
+		}
+		return bounces[i].BounceBacks > bounces[j].BounceBacks
+	})
+	if len(bounces) > topTasks {
+		bounces = bounces[:topTasks]
+	}
+	result.MostBounced = bounces
+
+	for _, stats := range groups {
+		stats.ReassignmentsPerTask = float64(stats.Reassignments) / float64(stats.Tasks)
+		result.Groups = append(result.Groups, *stats)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Reassignments != result.Groups[j].Reassignments {
+			return result.Groups[i].Reassignments > result.Groups[j].Reassignments
+		}
+		return result.Groups[i].Group < result.Groups[j].Group
+	})
+	return result
+}
@@ -0,0 +1,135 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeAssignments(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	base := time.Date(2026, 10, 1, 8, 0, 0, 0, jakarta)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+	until := func(hours int) *time.Time {
+		end := at(hours)
+		return &end
+	}
+	span := func(task, group, assignee string, start int, end *time.Time) AssignmentSpan {
+		return AssignmentSpan{TaskID: task, Group: group, Assignee: assignee, AssigneeName: "Name " + assignee, Start: at(start), End: end}
+	}
+	holder := func(empNo string, assignments, handedOff int, total, max float64) HolderStats {
+		return HolderStats{
+			EmpNo: empNo, EmpName: "Name " + empNo,
+			Assignments: assignments, HandedOff: handedOff,
+			TotalHoldHours: total, AvgHoldHours: total / float64(assignments), MaxHoldHours: max,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		spans []AssignmentSpan
+		now   time.Time
+		top   int
+		want  AssignmentAnalytics
+	}{
+		{
+			name: "bounce back, listed out of order",
+			spans: []AssignmentSpan{
+				span("T1", "G1", "B", 2, until(5)),
+				span("T1", "G1", "A", 5, until(6)),
+				span("T1", "G1", "A", 0, until(2)),
+			},
+			now: at(10),
+			top: 20,
+			want: AssignmentAnalytics{
+				Tasks: 1, TasksReassigned: 1, TotalReassignments: 2, TotalBounceBacks: 1,
+				Holders:     []HolderStats{holder("B", 1, 1, 3, 3), holder("A", 2, 2, 3, 2)},
+				MostBounced: []TaskBounce{{TaskID: "T1", Reassignments: 2, BounceBacks: 1, Holders: []string{"A", "B", "A"}}},
+				Groups:      []GroupReassignments{{Group: "G1", Tasks: 1, Reassignments: 2, ReassignmentsPerTask: 2}},
+			},
+		},
+		{
+			name: "same holder again is not a move",
+			spans: []AssignmentSpan{
+				span("T1", "", "A", 0, until(2)),
+				span("T1", "", "A", 2, until(4)),
+			},
+			now:  at(10),
+			top:  20,
+			want: AssignmentAnalytics{Tasks: 1, Holders: []HolderStats{holder("A", 2, 2, 4, 2)}},
+		},
+		{
+			name: "open span held until now",
+			spans: []AssignmentSpan{
+				span("T1", "G1", "A", 0, until(4)),
+				span("T1", "G1", "B", 4, nil),
+			},
+			now: at(10),
+			top: 20,
+			want: AssignmentAnalytics{
+				Tasks: 1, TasksReassigned: 1, TotalReassignments: 1,
+				Holders:     []HolderStats{holder("B", 1, 0, 6, 6), holder("A", 1, 1, 4, 4)},
+				MostBounced: []TaskBounce{{TaskID: "T1", Reassignments: 1, Holders: []string{"A", "B"}}},
+				Groups:      []GroupReassignments{{Group: "G1", Tasks: 1, Reassignments: 1, ReassignmentsPerTask: 1}},
+			},
+		},
+		{
+			name: "span ending before it starts holds nothing",
+			spans: []AssignmentSpan{
+				span("T1", "", "A", 3, until(1)),
+			},
+			now:  at(10),
+			top:  20,
+			want: AssignmentAnalytics{Tasks: 1, Holders: []HolderStats{{EmpNo: "A", EmpName: "Name A", Assignments: 1, HandedOff: 1}}},
+		},
+		{
+			name: "most bounced first, up to top",
+			spans: []AssignmentSpan{
+				span("T1", "G1", "A", 0, until(1)),
+				span("T1", "G1", "B", 1, until(2)),
+				span("T2", "G1", "A", 0, until(1)),
+				span("T2", "G1", "B", 1, until(2)),
+				span("T2", "G1", "C", 2, until(3)),
+				span("T3", "G2", "A", 0, until(1)),
+				span("T3", "G2", "B", 1, until(2)),
+				span("T3", "G2", "A", 2, until(3)),
+			},
+			now: at(10),
+			top: 2,
+			want: AssignmentAnalytics{
+				Tasks: 3, TasksReassigned: 3, TotalReassignments: 5, TotalBounceBacks: 1,
+				Holders: []HolderStats{holder("A", 4, 4, 4, 1), holder("B", 3, 3, 3, 1), holder("C", 1, 1, 1, 1)},
+				MostBounced: []TaskBounce{
+					{TaskID: "T3", Reassignments: 2, BounceBacks: 1, Holders: []string{"A", "B", "A"}},
+					{TaskID: "T2", Reassignments: 2, Holders: []string{"A", "B", "C"}},
+				},
+				Groups: []GroupReassignments{
+					{Group: "G1", Tasks: 2, Reassignments: 3, ReassignmentsPerTask: 1.5},
+					{Group: "G2", Tasks: 1, Reassignments: 2, ReassignmentsPerTask: 2},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := AnalyzeAssignments(test.spans, test.now, test.top)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("AnalyzeAssignments\n got %+v\nwant %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestAnalyzeAssignmentsServerZone holds an open span from a start scanned
+// as the driver returns it, the local wall clock labelled UTC.
+func TestAnalyzeAssignmentsServerZone(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	setLocal(t, jakarta)
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	LocalWallClocks(&start)
+	now := time.Date(2026, 10, 1, 10, 0, 0, 0, jakarta)
+	got := AnalyzeAssignments([]AssignmentSpan{{TaskID: "T1", Assignee: "A", Start: start}}, now, 20)
+	if len(got.Holders) != 1 || got.Holders[0].TotalHoldHours != 2 {
+		t.Errorf("holders = %+v, want A holding for 2 hours", got.Holders)
+	}
+}
@@ -0,0 +1,303 @@
+package helper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSBlobStore keeps objects in a GridFS bucket. Keys that are object ID
+// hex strings are stored as object IDs, so files uploaded before the store
+// existed keep their IDs.
+type GridFSBlobStore struct {
+	bucket *gridfs.Bucket
+}
+
+// gridfsFile is a document of the bucket's files collection.
+type gridfsFile struct {
+	ID         interface{} `bson:"_id"`
+	Length     int64       `bson:"length"`
+	ChunkSize  int32       `bson:"chunkSize"`
+	UploadDate time.Time   `bson:"uploadDate"`
+	Name       string      `bson:"filename"`
+	Metadata   bson.Raw    `bson:"metadata"`
+}
+
+// gridfsChunk is a document of the bucket's chunks collection.
+type gridfsChunk struct {
+	N    int64  `bson:"n"`
+	Data []byte `bson:"data"`
+}
+
+// NewGridFSBlobStore stores objects in bucket. The bucket is shared by all
+// requests, so its own deadlines are never set; each transfer sets one on
+// its stream instead.
+func NewGridFSBlobStore(bucket *gridfs.Bucket) *GridFSBlobStore {
+	return &GridFSBlobStore{bucket: bucket}
+}
+
+func (s *GridFSBlobStore) Kind() string {
+	return "gridfs"
+}
+
+const (
+	mongoBaseTimeout       = 10 * time.Second
+	mongoMinBytesPerSecond = 256 << 10
+)
+
+// transferTimeout is how long moving size bytes to or from MongoDB may
+// take: a fixed allowance plus the time the bytes need on a slow link.
+func transferTimeout(size int64) time.Duration {
+	return mongoBaseTimeout + time.Duration(size/mongoMinBytesPerSecond)*time.Second
+}
+
+// slidingDeadline gives every write to an upload stream of unknown length
+// its own deadline, sized to the bytes written.
+type slidingDeadline struct {
+	stream *gridfs.UploadStream
+}
+
+func (w slidingDeadline) Write(p []byte) (int, error) {
+	w.stream.SetWriteDeadline(time.Now().Add(transferTimeout(int64(len(p)))))
+	return w.stream.Write(p)
+}
+
+func gridfsID(key string) interface{} {
+	if id, err := primitive.ObjectIDFromHex(key); err == nil {
+		return id
+	}
+	return key
+}
+
+func gridfsKey(id interface{}) string {
+	switch id := id.(type) {
+	case primitive.ObjectID:
+		return id.Hex()
+	case string:
+		return id
+	}
+	return fmt.Sprint(id)
+}
+
+func (file gridfsFile) info() BlobInfo {
+	info := BlobInfo{
+		Key:        gridfsKey(file.ID),
+		Name:       file.Name,
+		Size:       file.Length,
+		UploadedAt: file.UploadDate,
+	}
+	if value, err := file.Metadata.LookupErr("contentType"); err == nil {
+		info.ContentType, _ = value.StringValueOK()
+	}
+	if value, err := file.Metadata.LookupErr("sha256"); err == nil {
+		info.SHA256, _ = value.StringValueOK()
+	}
+	info.ContentType = contentTypeOf(info)
+	return info
+}
+
+// Put sizes the upload's deadline to info.Size when the caller knows it, and
+// to each write otherwise.
+func (s *GridFSBlobStore) Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error) {
+	if info.Key == "" {
+		info.Key = NewBlobKey()
+	}
+	uploadStream, err := s.bucket.OpenUploadStreamWithID(gridfsID(info.Key), info.Name,
+		options.GridFSUpload().SetMetadata(bson.M{"contentType": info.ContentType}))
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	sliding := info.Size <= 0
+	var w io.Writer = uploadStream
+	if sliding {
+		w = slidingDeadline{uploadStream}
+	} else {
+		uploadStream.SetWriteDeadline(time.Now().Add(transferTimeout(info.Size)))
+	}
+	hash := sha256.New()
+	info.Size, err = io.Copy(w, io.TeeReader(contextReader{ctx, r}, hash))
+	if err != nil {
+		uploadStream.Abort()
+		return BlobInfo{}, err
+	}
+	if sliding {
+		uploadStream.SetWriteDeadline(time.Now().Add(mongoBaseTimeout))
+	}
+	if err := uploadStream.Close(); err != nil {
+		return BlobInfo{}, err
+	}
+	info.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	info.UploadedAt = time.Now()
+	_, err = s.bucket.GetFilesCollection().UpdateByID(ctx, gridfsID(info.Key),
+		bson.M{"$set": bson.M{"metadata.sha256": info.SHA256}})
+	if err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+func (s *GridFSBlobStore) Open(ctx context.Context, key string) (Blob, error) {
+	var file gridfsFile
+	err := s.bucket.GetFilesCollection().FindOne(ctx, bson.M{"_id": gridfsID(key)}).Decode(&file)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	chunkSize := int64(file.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = int64(gridfs.DefaultChunkSize)
+	}
+	return &gridfsBlob{info: file.info(), bucket: s.bucket, id: file.ID, chunkSize: chunkSize}, nil
+}
+
+func (s *GridFSBlobStore) Delete(ctx context.Context, key string) error {
+	err := s.bucket.DeleteContext(ctx, gridfsID(key))
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return ErrBlobNotFound
+	}
+	return err
+}
+
+func (s *GridFSBlobStore) Walk(ctx context.Context, fn func(BlobInfo) error) error {
+	cursor, err := s.bucket.FindContext(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var file gridfsFile
+		if err := cursor.Decode(&file); err != nil {
+			return err
+		}
+		if err := fn(file.info()); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// gridfsBlob reads a GridFS file. Seeking makes the next Read query the
+// chunks collection from the chunk that holds the new position, so the
+// chunks before it are never fetched; each query's deadline is sized to the
+// bytes left from where it starts.
+type gridfsBlob struct {
+	info      BlobInfo
+	bucket    *gridfs.Bucket
+	id        interface{}
+	chunkSize int64
+	chunks    *mongo.Cursor
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// buf is the unread part of the current chunk, which starts at bufPos;
+	// next is the number of the chunk after it.
+	buf    []byte
+	bufPos int64
+	next   int64
+	pos    int64
+}
+
+func (b *gridfsBlob) Info() BlobInfo {
+	return b.info
+}
+
+// openChunks starts reading the chunks at the one that holds b.pos.
+func (b *gridfsBlob) openChunks() error {
+	b.closeChunks()
+	first := b.pos / b.chunkSize
+	ctx, cancel := context.WithTimeout(context.Background(), transferTimeout(b.info.Size-b.pos))
+	cursor, err := b.bucket.GetChunksCollection().Find(ctx,
+		bson.M{"files_id": b.id, "n": bson.M{"$gte": first}},
+		options.Find().SetSort(bson.D{{Key: "n", Value: 1}}))
+	if err != nil {
+		cancel()
+		return err
+	}
+	b.chunks, b.ctx, b.cancel = cursor, ctx, cancel
+	b.next = first
+	if err := b.nextChunk(); err != nil {
+		return err
+	}
+	offset := b.pos - b.bufPos
+	if offset > int64(len(b.buf)) {
+		return fmt.Errorf("gridfs chunk %d of %v is short", first, b.id)
+	}
+	b.buf, b.bufPos = b.buf[offset:], b.pos
+	return nil
+}
+
+// nextChunk loads the chunk numbered b.next into buf.
+func (b *gridfsBlob) nextChunk() error {
+	if !b.chunks.Next(b.ctx) {
+		if err := b.chunks.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("gridfs chunk %d of %v is missing", b.next, b.id)
+	}
+	var chunk gridfsChunk
+	if err := b.chunks.Decode(&chunk); err != nil {
+		return err
+	}
+	if chunk.N != b.next {
+		return fmt.Errorf("gridfs chunk %d of %v is missing", b.next, b.id)
+	}
+	b.buf, b.bufPos = chunk.Data, b.next*b.chunkSize
+	b.next++
+	return nil
+}
+
+func (b *gridfsBlob) closeChunks() {
+	if b.chunks != nil {
+		b.chunks.Close(b.ctx)
+		b.cancel()
+		b.chunks, b.buf = nil, nil
+	}
+}
+
+func (b *gridfsBlob) Read(p []byte) (int, error) {
+	if b.pos >= b.info.Size {
+		return 0, io.EOF
+	}
+	if b.chunks == nil || b.bufPos != b.pos {
+		if err := b.openChunks(); err != nil {
+			b.closeChunks()
+			return 0, err
+		}
+	}
+	if len(b.buf) == 0 {
+		if err := b.nextChunk(); err != nil {
+			b.closeChunks()
+			return 0, err
+		}
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	b.bufPos += int64(n)
+	b.pos += int64(n)
+	return n, nil
+}
+
+func (b *gridfsBlob) Seek(offset int64, whence int) (int64, error) {
+	pos, err := seekOffset(b.pos, b.info.Size, offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	b.pos = pos
+	return pos, nil
+}
+
+func (b *gridfsBlob) Close() error {
+	b.closeChunks()
+	return nil
+}
@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const localBlobInfoExt = ".info.json"
+
+// LocalBlobStore keeps objects as files in a directory, each next to a JSON
+// file holding its BlobInfo. Keys are path-escaped, so they never leave the
+// directory.
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore stores objects in dir, creating it when needed.
+func NewLocalBlobStore(dir string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStore{dir: dir}, nil
+}
+
+func (s *LocalBlobStore) Kind() string {
+	return "local"
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, url.PathEscape(key)), nil
+}
+
+func (s *LocalBlobStore) readInfo(path string) (BlobInfo, error) {
+	var info BlobInfo
+	content, err := os.ReadFile(path + localBlobInfoExt)
+	if errors.Is(err, fs.ErrNotExist) {
+		return info, ErrBlobNotFound
+	}
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(content, &info)
+	return info, err
+}
+
+// Put writes to a temporary file first, so a failed upload leaves nothing
+// behind and readers never see a partial object.
+func (s *LocalBlobStore) Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error) {
+	if info.Key == "" {
+		info.Key = NewBlobKey()
+	}
+	path, err := s.path(info.Key)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	temp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	defer os.Remove(temp.Name())
+	hash := sha256.New()
+	info.Size, err = io.Copy(temp, io.TeeReader(contextReader{ctx, r}, hash))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	info.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	info.UploadedAt = time.Now()
+	content, err := json.Marshal(info)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return BlobInfo{}, err
+	}
+	if err := os.WriteFile(path+localBlobInfoExt, content, 0o644); err != nil {
+		os.Remove(path)
+		return BlobInfo{}, err
+	}
+	return info, nil
+}
+
+func (s *LocalBlobStore) Open(ctx context.Context, key string) (Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.readInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info.ContentType = contentTypeOf(info)
+	return localBlob{File: file, info: info}, nil
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path + localBlobInfoExt)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrBlobNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (s *LocalBlobStore) Walk(ctx context.Context, fn func(BlobInfo) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name, ok := strings.CutSuffix(entry.Name(), localBlobInfoExt)
+		if !ok {
+			continue
+		}
+		info, err := s.readInfo(filepath.Join(s.dir, name))
+		if err != nil {
+			return err
+		}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type localBlob struct {
+	*os.File
+	info BlobInfo
+}
+
+func (b localBlob) Info() BlobInfo {
+	return b.info
+}
@@ -0,0 +1,274 @@
+package helper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// S3BlobStore keeps objects in an S3-compatible bucket such as Cloudflare
+// R2. The file name and SHA-256 are kept in the object's user metadata.
+type S3BlobStore struct {
+	svc      *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+// NewS3BlobStore opens bucket on the S3-compatible endpoint.
+func NewS3BlobStore(endpoint, region, accessKey, secretKey, bucket string) (*S3BlobStore, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String(region),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(true), // required for Cloudflare R2
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %v", err)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("no bucket configured for the S3 blob store")
+	}
+	return &S3BlobStore{
+		svc:      s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+	}, nil
+}
+
+func (s *S3BlobStore) Kind() string {
+	return "s3"
+}
+
+// s3NotFound reports whether err is the 404 of a missing key.
+func s3NotFound(err error) bool {
+	if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == s3.ErrCodeNoSuchKey
+	}
+	return false
+}
+
+func s3Metadata(info BlobInfo) map[string]*string {
+	metadata := map[string]*string{"Name": aws.String(url.QueryEscape(info.Name))}
+	if info.SHA256 != "" {
+		metadata["Sha256"] = aws.String(info.SHA256)
+	}
+	return metadata
+}
+
+func s3Info(key string, head *s3.HeadObjectOutput) BlobInfo {
+	info := BlobInfo{
+		Key:         key,
+		Name:        key,
+		ContentType: aws.StringValue(head.ContentType),
+		Size:        aws.Int64Value(head.ContentLength),
+		UploadedAt:  aws.TimeValue(head.LastModified),
+	}
+	for name, value := range head.Metadata {
+		switch strings.ToLower(name) {
+		case "name":
+			if unescaped, err := url.QueryUnescape(aws.StringValue(value)); err == nil {
+				info.Name = unescaped
+			}
+		case "sha256":
+			info.SHA256 = aws.StringValue(value)
+		}
+	}
+	info.ContentType = contentTypeOf(info)
+	return info
+}
+
+func (s *S3BlobStore) head(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
+	head, err := s.svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if s3NotFound(err) {
+		return nil, ErrBlobNotFound
+	}
+	return head, err
+}
+
+// Put uploads r in parts, so its length need not be known. The SHA-256 is
+// stored in the object's metadata only when info carries it, as metadata
+// cannot change once the upload is done; an object whose bytes do not match
+// it is removed again.
+func (s *S3BlobStore) Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error) {
+	if info.Key == "" {
+		info.Key = NewBlobKey()
+	}
+	knownSum := info.SHA256
+	hash := sha256.New()
+	counter := &countingReader{r: io.TeeReader(r, hash)}
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(info.Key),
+		ContentType: aws.String(info.ContentType),
+		Metadata:    s3Metadata(info),
+		Body:        counter,
+	})
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to upload file: %v", err)
+	}
+	info.Size = counter.n
+	info.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	info.UploadedAt = time.Now()
+	if knownSum != "" && knownSum != info.SHA256 {
+		err := fmt.Errorf("sha256 %s does not match the expected %s", info.SHA256, knownSum)
+		if deleteErr := s.Delete(ctx, info.Key); deleteErr != nil {
+			err = errors.Join(err, deleteErr)
+		}
+		return BlobInfo{}, err
+	}
+	return info, nil
+}
+
+func (s *S3BlobStore) Open(ctx context.Context, key string) (Blob, error) {
+	head, err := s.head(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Blob{ctx: ctx, store: s, info: s3Info(key, head)}, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.head(ctx, key); err != nil {
+		return err
+	}
+	_, err := s.svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// Walk lists the bucket and reads the metadata of each object.
+func (s *S3BlobStore) Walk(ctx context.Context, fn func(BlobInfo) error) error {
+	var walkErr error
+	err := s.svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			key := aws.StringValue(object.Key)
+			head, err := s.head(ctx, key)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			if err := fn(s3Info(key, head)); err != nil {
+				walkErr = err
+				return false
+			}
+		}
+		return true
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	return err
+}
+
+func (s *S3BlobStore) PresignPut(key, contentType string, ttl time.Duration) (string, error) {
+	request, _ := s.svc.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	return request.Presign(ttl)
+}
+
+func (s *S3BlobStore) PresignGet(key, fileName string, ttl time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName}); disposition != "" {
+		input.ResponseContentDisposition = aws.String(disposition)
+	}
+	request, _ := s.svc.GetObjectRequest(input)
+	return request.Presign(ttl)
+}
+
+// s3Blob reads an object with ranged GETs from the current position.
+type s3Blob struct {
+	ctx     context.Context
+	store   *S3BlobStore
+	info    BlobInfo
+	body    io.ReadCloser
+	bodyPos int64
+	pos     int64
+}
+
+func (b *s3Blob) Info() BlobInfo {
+	return b.info
+}
+
+func (b *s3Blob) Read(p []byte) (int, error) {
+	if b.pos >= b.info.Size {
+		return 0, io.EOF
+	}
+	if b.body == nil || b.bodyPos != b.pos {
+		if b.body != nil {
+			b.body.Close()
+			b.body = nil
+		}
+		object, err := b.store.svc.GetObjectWithContext(b.ctx, &s3.GetObjectInput{
+			Bucket: aws.String(b.store.bucket),
+			Key:    aws.String(b.info.Key),
+			Range:  aws.String(fmt.Sprintf("bytes=%d-", b.pos)),
+		})
+		if err != nil {
+			return 0, err
+		}
+		b.body, b.bodyPos = object.Body, b.pos
+	}
+	n, err := b.body.Read(p)
+	b.pos += int64(n)
+	b.bodyPos += int64(n)
+	return n, err
+}
+
+func (b *s3Blob) Seek(offset int64, whence int) (int64, error) {
+	pos, err := seekOffset(b.pos, b.info.Size, offset, whence)
+	if err != nil {
+		return 0, err
+	}
+	b.pos = pos
+	return pos, nil
+}
+
+func (b *s3Blob) Close() error {
+	if b.body != nil {
+		return b.body.Close()
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,187 @@
+package helper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+// ErrBlobNotFound is returned by BlobStore.Open and BlobStore.Delete when no
+// object is stored under the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobInfo describes an object of a BlobStore.
+type BlobInfo struct {
+	Key         string    `json:"object_id"`
+	Name        string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// Blob is an opened object. A Read after a Seek fetches the object from the
+// new position on, or on GridFS from the chunk holding it, never the bytes
+// before it, so it can be served with Range support.
+type Blob interface {
+	io.ReadSeekCloser
+	Info() BlobInfo
+}
+
+// BlobStore keeps uploaded files. Keys are chosen by the store when Put is
+// given none; new keys are object ID hex strings on every backend, so they
+// stay valid when objects are migrated.
+type BlobStore interface {
+	// Kind names the backend: gridfs, s3 or local.
+	Kind() string
+	// Put stores r under info.Key, or under a new key when it is empty,
+	// without buffering it, and returns the stored object. info.Size and
+	// info.SHA256 may carry the expected length and checksum when the
+	// caller knows them.
+	Put(ctx context.Context, info BlobInfo, r io.Reader) (BlobInfo, error)
+	// Open opens the object stored under key. The caller must Close it.
+	Open(ctx context.Context, key string) (Blob, error)
+	// Delete removes the object stored under key.
+	Delete(ctx context.Context, key string) error
+	// Walk calls fn with every stored object until fn returns an error.
+	Walk(ctx context.Context, fn func(BlobInfo) error) error
+}
+
+// BlobPresigner is implemented by stores that can hand out short-lived URLs
+// for clients to upload and download objects without going through the API.
+type BlobPresigner interface {
+	// PresignPut returns a URL the client PUTs the object to. The request
+	// must carry contentType as its Content-Type.
+	PresignPut(key, contentType string, ttl time.Duration) (string, error)
+	// PresignGet returns a URL that downloads the object as fileName.
+	PresignGet(key, fileName string, ttl time.Duration) (string, error)
+}
+
+// NewBlobKey returns a key for a new object.
+func NewBlobKey() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// NewBlobStore opens the backend named by kind. The gridfs backend keeps
+// objects in storeDoc; the others are configured from the environment:
+//
+//	s3      endpoint, accessKey, secretKey, BucketName, BLOB_S3_REGION (auto)
+//	local   BLOB_LOCAL_DIR (blobs)
+//
+// An empty kind selects gridfs.
+func NewBlobStore(kind string, storeDoc *gridfs.Bucket) (BlobStore, error) {
+	switch strings.ToLower(kind) {
+	case "", "gridfs":
+		if storeDoc == nil {
+			return nil, errors.New("the gridfs blob store needs MongoDB")
+		}
+		return NewGridFSBlobStore(storeDoc), nil
+	case "s3", "r2":
+		region := GodotEnv("BLOB_S3_REGION")
+		if region == "" {
+			region = "auto"
+		}
+		return NewS3BlobStore(GodotEnv("endpoint"), region, GodotEnv("accessKey"), GodotEnv("secretKey"), GodotEnv("BucketName"))
+	case "local":
+		dir := GodotEnv("BLOB_LOCAL_DIR")
+		if dir == "" {
+			dir = "blobs"
+		}
+		return NewLocalBlobStore(dir)
+	}
+	return nil, fmt.Errorf("unknown blob store %q, expected gridfs, s3 or local", kind)
+}
+
+// PutFile stores the local file at path under key, or under a new key when
+// key is empty, as name, or as the file's base name when name is empty. The
+// file is hashed before it is stored, so stores can record its SHA-256 with
+// the upload.
+func PutFile(ctx context.Context, store BlobStore, path, key, name string) (BlobInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return BlobInfo{}, err
+	}
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(file, head)
+		contentType = http.DetectContentType(head[:n])
+	}
+	hash := sha256.New()
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return BlobInfo{}, err
+	}
+	if _, err := io.Copy(hash, contextReader{ctx, file}); err != nil {
+		return BlobInfo{}, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return BlobInfo{}, err
+	}
+	return store.Put(ctx, BlobInfo{
+		Key:         key,
+		Name:        name,
+		ContentType: contentType,
+		Size:        stat.Size(),
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+	}, file)
+}
+
+// contentTypeOf falls back to the extension of the file name when no
+// content type was stored.
+func contentTypeOf(info BlobInfo) string {
+	if info.ContentType != "" {
+		return info.ContentType
+	}
+	return mime.TypeByExtension(filepath.Ext(info.Name))
+}
+
+// seekOffset resolves a Seek call on an object of the given size.
+func seekOffset(pos, size, offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += pos
+	case io.SeekEnd:
+		offset += size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	return offset, nil
+}
+
+// contextReader stops a copy once ctx is done, for backends whose writers
+// take no context.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
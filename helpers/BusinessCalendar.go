@@ -0,0 +1,282 @@
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// WorkingHours is the working window of one weekday, as minutes after
+// midnight. A day with Working set to false is a rest day.
+type WorkingHours struct {
+	Working bool
+	Start   int
+	End     int
+}
+
+// BusinessCalendar answers working-day questions for one department: the
+// weekly working pattern, public holidays and department closures.
+type BusinessCalendar struct {
+	week     [7]WorkingHours
+	holidays map[string]string
+	closures map[string]string
+}
+
+// DefaultWorkingWeek is Monday to Friday, 08:00 to 17:00.
+func DefaultWorkingWeek() [7]WorkingHours {
+	var week [7]WorkingHours
+	for d := time.Monday; d <= time.Friday; d++ {
+		week[d] = WorkingHours{Working: true, Start: 8 * 60, End: 17 * 60}
+	}
+	return week
+}
+
+// NewBusinessCalendar builds a calendar from a weekly pattern.
+func NewBusinessCalendar(week [7]WorkingHours) *BusinessCalendar {
+	return &BusinessCalendar{
+		week:     week,
+		holidays: make(map[string]string),
+		closures: make(map[string]string),
+	}
+}
+
+// AddHoliday marks a date as a public holiday.
+func (cal *BusinessCalendar) AddHoliday(date time.Time, name string) {
+	cal.holidays[date.Format(dateLayout)] = name
+}
+
+// AddClosure marks every date from start to end inclusive as closed.
+func (cal *BusinessCalendar) AddClosure(start, end time.Time, reason string) {
+	for d := truncateDay(start); !d.After(truncateDay(end)); d = d.AddDate(0, 0, 1) {
+		cal.closures[d.Format(dateLayout)] = reason
+	}
+}
+
+// NonWorkingReason explains why a date is not a working day, or returns
+// an empty string when it is one.
+func (cal *BusinessCalendar) NonWorkingReason(t time.Time) string {
+	key := t.Format(dateLayout)
+	if name, ok := cal.holidays[key]; ok {
+		return "Holiday: " + name
+	}
+	if reason, ok := cal.closures[key]; ok {
+		return "Closure: " + reason
+	}
+	if !cal.week[t.Weekday()].Working {
+		return "Rest day: " + t.Weekday().String()
+	}
+	return ""
+}
+
+// IsWorkingDay reports whether t falls on a working day.
+func (cal *BusinessCalendar) IsWorkingDay(t time.Time) bool {
+	return cal.NonWorkingReason(t) == ""
+}
+
+// AddWorkingDays moves t by n working days, backwards when n is negative.
+// The time of day is kept.
+func (cal *BusinessCalendar) AddWorkingDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for i := 0; i < maxRecurrenceScan && n > 0; i++ {
+		t = t.AddDate(0, 0, step)
+		if cal.IsWorkingDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// NextWorkingDay returns t when it is a working day, otherwise the first
+// working day after it.
+func (cal *BusinessCalendar) NextWorkingDay(t time.Time) time.Time {
+	for i := 0; i < maxRecurrenceScan && !cal.IsWorkingDay(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// PreviousWorkingDay returns t when it is a working day, otherwise the last
+// working day before it.
+func (cal *BusinessCalendar) PreviousWorkingDay(t time.Time) time.Time {
+	for i := 0; i < maxRecurrenceScan && !cal.IsWorkingDay(t); i++ {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// WorkingDaysBetween counts the working days after from up to and including
+// to. It is negative when to is before from.
+func (cal *BusinessCalendar) WorkingDaysBetween(from, to time.Time) int {
+	sign := 1
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) {
+		sign, from, to = -1, to, from
+	}
+	count := 0
+	for d := from.AddDate(0, 0, 1); !d.After(to); d = d.AddDate(0, 0, 1) {
+		if cal.IsWorkingDay(d) {
+			count++
+		}
+	}
+	return sign * count
+}
+
+// WorkingDuration returns the working time between from and to, counting
+// only the working window of working days.
+func (cal *BusinessCalendar) WorkingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	var total time.Duration
+	for d := truncateDay(from); d.Before(to); d = d.AddDate(0, 0, 1) {
+		start, end, ok := cal.window(d)
+		if !ok {
+			continue
+		}
+		if from.After(start) {
+			start = from
+		}
+		if to.Before(end) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// AddWorkingDuration returns the moment reached after working for d from t.
+func (cal *BusinessCalendar) AddWorkingDuration(t time.Time, d time.Duration) time.Time {
+	for day, i := truncateDay(t), 0; i < maxRecurrenceScan; day, i = day.AddDate(0, 0, 1), i+1 {
+		start, end, ok := cal.window(day)
+		if !ok || !end.After(t) {
+			continue
+		}
+		if t.After(start) {
+			start = t
+		}
+		available := end.Sub(start)
+		if d <= available {
+			return start.Add(d)
+		}
+		d -= available
+	}
+	return t
+}
+
+func (cal *BusinessCalendar) window(day time.Time) (time.Time, time.Time, bool) {
+	hours := cal.week[day.Weekday()]
+	if !cal.IsWorkingDay(day) || hours.End <= hours.Start {
+		return time.Time{}, time.Time{}, false
+	}
+	start := day.Add(time.Duration(hours.Start) * time.Minute)
+	end := day.Add(time.Duration(hours.End) * time.Minute)
+	return start, end, true
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight.
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ICalHoliday is one day taken from an iCalendar VEVENT.
+type ICalHoliday struct {
+	Date time.Time
+	Name string
+}
+
+// ParseICalHolidays reads the all-day VEVENTs of an iCalendar file, such as
+// the published Indonesian public holiday calendars. Events spanning
+// several days yield one entry per day.
+func ParseICalHolidays(r io.Reader, loc *time.Location) ([]ICalHoliday, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Folded lines continue with a leading space or tab.
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var holidays []ICalHoliday
+	var inEvent bool
+	var start, end time.Time
+	var summary string
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		property := strings.ToUpper(strings.SplitN(name, ";", 2)[0])
+		switch {
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", summary)
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			// DTEND is exclusive for all-day events.
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				holidays = append(holidays, ICalHoliday{Date: d, Name: summary})
+			}
+		case !inEvent:
+		case property == "DTSTART":
+			t, err := parseICalDate(value, loc)
+			if err != nil {
+				return nil, err
+			}
+			start = t
+		case property == "DTEND":
+			t, err := parseICalDate(value, loc)
+			if err != nil {
+				return nil, err
+			}
+			end = t
+		case property == "SUMMARY":
+			summary = unescapeICal(value)
+		}
+	}
+	return holidays, nil
+}
+
+func parseICalDate(value string, loc *time.Location) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q", value)
+	}
+	t, err := time.ParseInLocation("20060102", value[:8], loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q", value)
+	}
+	return t, nil
+}
+
+func unescapeICal(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
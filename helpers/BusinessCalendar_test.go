@@ -0,0 +1,162 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCalendar is a Monday to Friday, 08:00 to 17:00 week in Jakarta, with
+// a holiday on Wednesday 21 October 2026 and the office closed from Friday
+// 23 to Monday 26 October.
+func testCalendar(t *testing.T) (*BusinessCalendar, *time.Location) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	cal := NewBusinessCalendar(DefaultWorkingWeek())
+	cal.AddHoliday(time.Date(2026, 10, 21, 0, 0, 0, 0, jakarta), "Test Day")
+	cal.AddClosure(time.Date(2026, 10, 23, 0, 0, 0, 0, jakarta), time.Date(2026, 10, 26, 0, 0, 0, 0, jakarta), "Office move")
+	return cal, jakarta
+}
+
+func TestBusinessCalendarNonWorkingReason(t *testing.T) {
+	cal, jakarta := testCalendar(t)
+	tests := []struct {
+		day  int
+		want string
+	}{
+		{19, ""},
+		{21, "Holiday: Test Day"},
+		{23, "Closure: Office move"},
+		{24, "Closure: Office move"},
+		{26, "Closure: Office move"},
+		{27, ""},
+		{31, "Rest day: Saturday"},
+	}
+	for _, test := range tests {
+		date := time.Date(2026, 10, test.day, 13, 0, 0, 0, jakarta)
+		if got := cal.NonWorkingReason(date); got != test.want {
+			t.Errorf("NonWorkingReason(%s) = %q, want %q", date.Format(dateLayout), got, test.want)
+		}
+	}
+}
+
+func TestBusinessCalendarWorkingDays(t *testing.T) {
+	cal, jakarta := testCalendar(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, jakarta)
+	}
+	addTests := []struct {
+		name string
+		from time.Time
+		n    int
+		want time.Time
+	}{
+		{"next day", at(19, 10, 0), 1, at(20, 10, 0)},
+		{"over a holiday", at(19, 10, 0), 2, at(22, 10, 0)},
+		{"over a closure and weekend", at(19, 10, 0), 3, at(27, 10, 0)},
+		{"backwards over a closure", at(27, 10, 0), -1, at(22, 10, 0)},
+		{"zero", at(24, 10, 0), 0, at(24, 10, 0)},
+	}
+	for _, test := range addTests {
+		if got := cal.AddWorkingDays(test.from, test.n); !got.Equal(test.want) {
+			t.Errorf("%s: AddWorkingDays(%s, %d) = %s, want %s", test.name, test.from.Format(occurrenceLayout), test.n, got.Format(occurrenceLayout), test.want.Format(occurrenceLayout))
+		}
+	}
+
+	if got := cal.NextWorkingDay(at(23, 9, 0)); !got.Equal(at(27, 9, 0)) {
+		t.Errorf("NextWorkingDay = %s, want 2026-10-27", got.Format(occurrenceLayout))
+	}
+	if got := cal.PreviousWorkingDay(at(26, 9, 0)); !got.Equal(at(22, 9, 0)) {
+		t.Errorf("PreviousWorkingDay = %s, want 2026-10-22", got.Format(occurrenceLayout))
+	}
+	if got := cal.WorkingDaysBetween(at(19, 23, 0), at(27, 1, 0)); got != 3 {
+		t.Errorf("WorkingDaysBetween = %d, want 3", got)
+	}
+	if got := cal.WorkingDaysBetween(at(27, 1, 0), at(19, 23, 0)); got != -3 {
+		t.Errorf("WorkingDaysBetween reversed = %d, want -3", got)
+	}
+}
+
+func TestBusinessCalendarWorkingDuration(t *testing.T) {
+	cal, jakarta := testCalendar(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, jakarta)
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"same day", at(19, 9, 0), at(19, 11, 30), 150 * time.Minute},
+		{"before and after hours", at(19, 6, 0), at(19, 20, 0), 9 * time.Hour},
+		{"overnight", at(19, 16, 0), at(20, 9, 30), 150 * time.Minute},
+		{"over a holiday", at(20, 16, 0), at(22, 9, 0), 2 * time.Hour},
+		{"over a closure and weekend", at(22, 16, 0), at(27, 9, 0), 2 * time.Hour},
+		{"rest day", at(31, 9, 0), at(31, 12, 0), 0},
+		{"reversed", at(20, 9, 0), at(19, 9, 0), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := cal.WorkingDuration(test.from, test.to); got != test.want {
+				t.Errorf("WorkingDuration = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	addTests := []struct {
+		name string
+		from time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{"within the day", at(19, 9, 0), 2 * time.Hour, at(19, 11, 0)},
+		{"before hours", at(19, 7, 0), time.Hour, at(19, 9, 0)},
+		{"over a holiday", at(20, 16, 0), 2 * time.Hour, at(22, 9, 0)},
+		{"after hours over a closure", at(22, 17, 0), 30 * time.Minute, at(27, 8, 30)},
+		{"exactly to closing", at(19, 16, 0), time.Hour, at(19, 17, 0)},
+	}
+	for _, test := range addTests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := cal.AddWorkingDuration(test.from, test.d); !got.Equal(test.want) {
+				t.Errorf("AddWorkingDuration = %s, want %s", got.Format(occurrenceLayout), test.want.Format(occurrenceLayout))
+			}
+		})
+	}
+}
+
+func TestParseICalHolidays(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20260320",
+		"DTEND;VALUE=DATE:20260322",
+		"SUMMARY:Hari Raya Idul Fitri\\, 1447",
+		" H",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"SUMMARY:Hari Raya Natal",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	holidays, err := ParseICalHolidays(strings.NewReader(ics), jakarta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, holiday := range holidays {
+		got = append(got, holiday.Date.In(jakarta).Format(dateLayout)+" "+holiday.Name)
+	}
+	want := []string{
+		"2026-03-20 Hari Raya Idul Fitri, 1447H",
+		"2026-03-21 Hari Raya Idul Fitri, 1447H",
+		"2026-12-25 Hari Raya Natal",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ParseICalHolidays\n got %q\nwant %q", got, want)
+	}
+
+	if _, err := ParseICalHolidays(strings.NewReader("BEGIN:VEVENT\r\nSUMMARY:x\r\nEND:VEVENT"), jakarta); err == nil {
+		t.Error("ParseICalHolidays accepted an event without DTSTART")
+	}
+}
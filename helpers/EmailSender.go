@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const EmailSenderURL = "http://192.168.10.203:6069/api/v1/create_email_sender"
+
+// SendEmailTemplate asks the email sender service to render one of its HTML
+// templates. params fill param1 to param10 in order.
+func SendEmailTemplate(emailTo, template string, params ...string) error {
+	if len(params) > 10 {
+		return fmt.Errorf("email templates take at most 10 params, got %d", len(params))
+	}
+	emailData := map[string]interface{}{
+		"email_from":     "SiPAM Notifications (No-Reply)",
+		"email_to":       emailTo, // Jika lebih satu email kasih tnada koma (,)
+		"email_cc":       "",
+		"email_template": template,
+		"email_subject":  "Notification",
+		"email_body":     "",
+		"email_category": "Notification",
+	}
+	for i := 1; i <= 10; i++ {
+		value := ""
+		if i <= len(params) {
+			value = params[i-1]
+		}
+		emailData["param"+strconv.Itoa(i)] = value
+	}
+	jsonData, err := json.Marshal(emailData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email data: %w", err)
+	}
+	response, err := http.Post(EmailSenderURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("email sender returned %s", response.Status)
+	}
+	return nil
+}
+
+// TaskLinkButton renders the "Show Your Task Here" button used in emails.
+func TaskLinkButton(taskID, label string) string {
+	return "<a style='background-color: rgb(255, 198, 39); color: white; padding: 15px 32px; text-align: center; text-decoration: none; display: inline-block; font-size: 16px; border-radius: 8px;' href='http://192.168.4.250/sipam/#/tasklist?Taskid=" + taskID + "'>" + label + "</a>"
+}
@@ -0,0 +1,535 @@
+package helper
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RenderMarkdown turns task descriptions and comments written in Markdown
+// into HTML that is safe to embed in pages and emails. Raw HTML in the
+// source is escaped, never passed through, and links are only kept for
+// http, https and mailto URLs. It covers the common subset: headings,
+// paragraphs, emphasis, code, quotes, lists, tables, rules and links.
+func RenderMarkdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	var out strings.Builder
+	renderMarkdownBlocks(&out, strings.Split(src, "\n"), 0)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+var (
+	mdHeading        = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*))?$`)
+	mdClosingHashes  = regexp.MustCompile(`(^|\s+)#+$`)
+	mdListItem       = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	mdTableSeparator = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+const mdPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isMarkdownRule(trimmed string) bool {
+	if len(trimmed) < 3 || !strings.ContainsAny(trimmed[:1], "-*_") {
+		return false
+	}
+	count := 0
+	for _, r := range trimmed {
+		switch {
+		case r == rune(trimmed[0]):
+			count++
+		case r != ' ':
+			return false
+		}
+	}
+	return count >= 3
+}
+
+func isMarkdownFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+func isMarkdownTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !strings.Contains(lines[i+1], "|") || !mdTableSeparator.MatchString(lines[i+1]) {
+		return false
+	}
+	return len(splitTableRow(lines[i])) == len(splitTableRow(lines[i+1]))
+}
+
+// startsMarkdownBlock reports whether lines[i] ends a paragraph.
+func startsMarkdownBlock(lines []string, i int) bool {
+	trimmed := strings.TrimSpace(lines[i])
+	return isMarkdownFence(trimmed) || isMarkdownRule(trimmed) || mdHeading.MatchString(lines[i]) ||
+		strings.HasPrefix(trimmed, ">") || mdListItem.MatchString(lines[i]) || isMarkdownTableStart(lines, i)
+}
+
+func renderMarkdownBlocks(out *strings.Builder, lines []string, depth int) {
+	if depth > maxMarkdownNesting {
+		out.WriteString("<p>" + html.EscapeString(strings.TrimSpace(strings.Join(lines, "\n"))) + "</p>\n")
+		return
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+		case isMarkdownFence(trimmed):
+			fence := trimmed[:3]
+			end := i + 1
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), fence) {
+				end++
+			}
+			out.WriteString("<pre><code>")
+			out.WriteString(html.EscapeString(strings.Join(lines[i+1:end], "\n")))
+			out.WriteString("</code></pre>\n")
+			i = end + 1
+		case isMarkdownRule(trimmed):
+			out.WriteString("<hr>\n")
+			i++
+		case mdHeading.MatchString(line):
+			match := mdHeading.FindStringSubmatch(line)
+			level := strconv.Itoa(len(match[1]))
+			text := mdClosingHashes.ReplaceAllString(strings.TrimSpace(match[2]), "")
+			out.WriteString("<h" + level + ">" + renderMarkdownInline(text) + "</h" + level + ">\n")
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				text := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(text, " "))
+				i++
+			}
+			out.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(out, quoted, depth+1)
+			out.WriteString("</blockquote>\n")
+		case isMarkdownTableStart(lines, i):
+			i = renderMarkdownTable(out, lines, i)
+		case mdListItem.MatchString(line):
+			i = renderMarkdownList(out, lines, i, depth)
+		default:
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "" && !startsMarkdownBlock(lines, end) {
+				end++
+			}
+			rendered := make([]string, 0, end-i)
+			for _, text := range lines[i:end] {
+				rendered = append(rendered, renderMarkdownInline(strings.TrimSpace(text)))
+			}
+			out.WriteString("<p>" + strings.Join(rendered, "<br>\n") + "</p>\n")
+			i = end
+		}
+	}
+}
+
+func isOrderedMarker(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+// renderMarkdownList renders the list starting at lines[i] and returns the
+// index of the first line after it. Lines indented past the marker belong
+// to the item, which is how nested lists are written.
+func renderMarkdownList(out *strings.Builder, lines []string, i, depth int) int {
+	first := mdListItem.FindStringSubmatch(lines[i])
+	indent := len(first[1])
+	ordered := isOrderedMarker(first[2])
+	sameList := func(line string) []string {
+		match := mdListItem.FindStringSubmatch(line)
+		if match == nil || len(match[1]) != indent || isOrderedMarker(match[2]) != ordered || isMarkdownRule(strings.TrimSpace(line)) {
+			return nil
+		}
+		return match
+	}
+
+	if !ordered {
+		out.WriteString("<ul>\n")
+	} else if start, _ := strconv.Atoi(strings.TrimRight(first[2], ".)")); start != 1 {
+		out.WriteString("<ol start=\"" + strconv.Itoa(start) + "\">\n")
+	} else {
+		out.WriteString("<ol>\n")
+	}
+	for i < len(lines) {
+		match := sameList(lines[i])
+		if match == nil {
+			break
+		}
+		contentIndent := len(match[0]) - len(match[3])
+		item := []string{match[3]}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				if i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" && leadingSpaces(lines[i+1]) > indent {
+					item = append(item, "")
+					i++
+					continue
+				}
+				break
+			}
+			if lead := leadingSpaces(line); lead > indent {
+				item = append(item, line[min(lead, contentIndent):])
+				i++
+				continue
+			}
+			if startsMarkdownBlock(lines, i) {
+				break
+			}
+			item = append(item, strings.TrimSpace(line))
+			i++
+		}
+
+		var inner strings.Builder
+		renderMarkdownBlocks(&inner, item, depth+1)
+		content := inner.String()
+		// A single paragraph is shown without <p>, as in a tight list.
+		if strings.HasPrefix(content, "<p>") && strings.Count(content, "<p>") == 1 {
+			end := strings.Index(content, "</p>\n")
+			content = content[len("<p>"):end] + "\n" + content[end+len("</p>\n"):]
+		}
+		out.WriteString("<li>" + strings.TrimSuffix(content, "\n") + "</li>\n")
+
+		if i+1 < len(lines) && strings.TrimSpace(lines[i]) == "" && sameList(lines[i+1]) != nil {
+			i++
+		}
+	}
+	if ordered {
+		out.WriteString("</ol>\n")
+	} else {
+		out.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// splitTableRow splits "| a | b |" into its cells. A "\|" stays in the cell.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[start:i]))
+			start = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+func renderMarkdownTable(out *strings.Builder, lines []string, i int) int {
+	header := splitTableRow(lines[i])
+	aligns := make([]string, len(header))
+	for k, cell := range splitTableRow(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns[k] = ` style="text-align:center"`
+		case strings.HasSuffix(cell, ":"):
+			aligns[k] = ` style="text-align:right"`
+		case strings.HasPrefix(cell, ":"):
+			aligns[k] = ` style="text-align:left"`
+		}
+	}
+	writeRow := func(cells []string, tag string) {
+		out.WriteString("<tr>")
+		for k := range header {
+			cell := ""
+			if k < len(cells) {
+				cell = cells[k]
+			}
+			out.WriteString("<" + tag + aligns[k] + ">" + renderMarkdownInline(cell) + "</" + tag + ">")
+		}
+		out.WriteString("</tr>\n")
+	}
+
+	out.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	out.WriteString("</thead>\n<tbody>\n")
+	i += 2
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|") {
+		writeRow(splitTableRow(lines[i]), "td")
+		i++
+	}
+	out.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+func isMarkdownWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isMarkdownSpace(c byte) bool {
+	return c == ' ' || c == '\n'
+}
+
+// safeMarkdownURL keeps only absolute http, https and mailto links.
+func safeMarkdownURL(href string) (string, bool) {
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return parsed.String(), true
+	}
+	return "", false
+}
+
+func markdownLink(href, label string) string {
+	return `<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer" target="_blank">` + label + `</a>`
+}
+
+// maxMarkdownNesting bounds how deep quotes, lists, links and emphasis are
+// parsed inside each other. Deeper content is shown as plain text, so
+// crafted input cannot make rendering quadratic or exhaust the stack.
+const maxMarkdownNesting = 16
+
+// maxMarkdownAutolink is the longest bare URL turned into a link, which
+// bounds the scan made at every "http" in the text.
+const maxMarkdownAutolink = 2048
+
+// inlineMarkdown renders one run of inline text. It matches the brackets
+// and parentheses once up front and remembers where closers were searched
+// for in vain, so every position is scanned a bounded number of times.
+type inlineMarkdown struct {
+	s string
+	// closeBracket and closeParen map an opening '[' or '(' to the index
+	// of its match.
+	closeBracket map[int]int
+	closeParen   map[int]int
+	// noEmphasis maps a delimiter to a scan start from which it has no
+	// closer; noBackticks does the same for a backtick run length.
+	noEmphasis  map[string]int
+	noBackticks map[int]int
+}
+
+func newInlineMarkdown(s string) *inlineMarkdown {
+	m := &inlineMarkdown{
+		s:            s,
+		closeBracket: map[int]int{},
+		closeParen:   map[int]int{},
+		noEmphasis:   map[string]int{},
+		noBackticks:  map[int]int{},
+	}
+	var brackets, parens []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			brackets = append(brackets, i)
+		case ']':
+			if n := len(brackets); n > 0 {
+				m.closeBracket[brackets[n-1]] = i
+				brackets = brackets[:n-1]
+			}
+		}
+	}
+	// Parentheses are matched without escapes, as URLs keep backslashes.
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			parens = append(parens, i)
+		case ')':
+			if n := len(parens); n > 0 {
+				m.closeParen[parens[n-1]] = i
+				parens = parens[:n-1]
+			}
+		}
+	}
+	return m
+}
+
+// link reads "[label](href)" at s[start] and returns the index after it.
+func (m *inlineMarkdown) link(start int) (label, href string, end int, ok bool) {
+	s := m.s
+	closeLabel, found := m.closeBracket[start]
+	if !found || closeLabel+1 >= len(s) || s[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+	closeHref, found := m.closeParen[closeLabel+1]
+	if !found {
+		return "", "", 0, false
+	}
+	href = strings.TrimSpace(s[closeLabel+2 : closeHref])
+	if space := strings.IndexByte(href, ' '); space >= 0 {
+		href = href[:space] // drop the title
+	}
+	return s[start+1 : closeLabel], strings.Trim(href, "<>"), closeHref + 1, true
+}
+
+// emphasis reads "**strong**", "__strong__", "*em*", "_em_" or "~~del~~" at
+// s[i].
+func (m *inlineMarkdown) emphasis(i int) (tag, inner string, end int, ok bool) {
+	s := m.s
+	delim := s[i : i+1]
+	if i+1 < len(s) && s[i+1] == s[i] {
+		delim = s[i : i+2]
+	}
+	switch delim {
+	case "**", "__":
+		tag = "strong"
+	case "*", "_":
+		tag = "em"
+	case "~~":
+		tag = "del"
+	default:
+		return "", "", 0, false
+	}
+	open := i + len(delim)
+	if open >= len(s) || isMarkdownSpace(s[open]) {
+		return "", "", 0, false
+	}
+	if delim[0] == '_' && i > 0 && isMarkdownWordByte(s[i-1]) {
+		return "", "", 0, false
+	}
+	// Whether a position closes depends only on the position, and two
+	// scans step in line once both pass a byte that is neither a backslash
+	// nor the delimiter. So past such a byte, a scan that starts after one
+	// that failed fails too.
+	failedFrom, failed := m.noEmphasis[delim]
+	for k := open + 1; k+len(delim) <= len(s); k++ {
+		if s[k] == '\\' {
+			k++
+			continue
+		}
+		if s[k] != delim[0] && failed && k >= failedFrom {
+			return "", "", 0, false
+		}
+		if s[k:k+len(delim)] != delim || isMarkdownSpace(s[k-1]) {
+			continue
+		}
+		if len(delim) == 1 && k+1 < len(s) && s[k+1] == delim[0] {
+			k++
+			continue
+		}
+		after := k + len(delim)
+		if delim[0] == '_' && after < len(s) && isMarkdownWordByte(s[after]) {
+			continue
+		}
+		return tag, s[open:k], after, true
+	}
+	if !failed {
+		m.noEmphasis[delim] = open + 1
+	}
+	return "", "", 0, false
+}
+
+// closingBackticks finds the run of exactly n backticks that closes a code
+// span, starting at s[from].
+func (m *inlineMarkdown) closingBackticks(from, n int) int {
+	s := m.s
+	if failedFrom, failed := m.noBackticks[n]; failed && from >= failedFrom {
+		return -1
+	}
+	for k := from; k < len(s); {
+		if s[k] != '`' {
+			k++
+			continue
+		}
+		run := len(s[k:]) - len(strings.TrimLeft(s[k:], "`"))
+		if run == n {
+			return k
+		}
+		k += run
+	}
+	m.noBackticks[n] = from
+	return -1
+}
+
+func renderMarkdownInline(s string) string {
+	return renderMarkdownInlineAt(s, 0, false)
+}
+
+// renderMarkdownInlineAt renders s nested depth levels deep. Inside a link
+// label, links keep only their label, as anchors cannot nest.
+func renderMarkdownInlineAt(s string, depth int, inLink bool) string {
+	m := newInlineMarkdown(s)
+	nested := depth < maxMarkdownNesting
+	var out strings.Builder
+	text := 0
+	flush := func(end int) {
+		out.WriteString(html.EscapeString(s[text:end]))
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(mdPunctuation, s[i+1]) >= 0:
+			flush(i)
+			out.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			text = i
+			continue
+		case c == '`':
+			ticks := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			if k := m.closingBackticks(i+ticks, ticks); k >= 0 {
+				flush(i)
+				code := s[i+ticks : k]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i = k + ticks
+				text = i
+				continue
+			}
+			i += ticks
+			continue
+		case nested && (c == '[' || (c == '!' && i+1 < len(s) && s[i+1] == '[')):
+			start := i
+			if c == '!' {
+				start++ // images are shown as links to them
+			}
+			if label, href, end, ok := m.link(start); ok {
+				flush(i)
+				if safe, ok := safeMarkdownURL(href); ok && !inLink {
+					out.WriteString(markdownLink(safe, renderMarkdownInlineAt(label, depth+1, true)))
+				} else {
+					out.WriteString(renderMarkdownInlineAt(label, depth+1, inLink))
+				}
+				i = end
+				text = i
+				continue
+			}
+		case nested && (c == '*' || c == '_' || c == '~'):
+			if tag, inner, end, ok := m.emphasis(i); ok {
+				flush(i)
+				out.WriteString("<" + tag + ">" + renderMarkdownInlineAt(inner, depth+1, inLink) + "</" + tag + ">")
+				i = end
+				text = i
+				continue
+			}
+		case c == 'h' && !inLink && (i == 0 || !isMarkdownWordByte(s[i-1])) &&
+			(strings.HasPrefix(s[i:], "http://") || strings.HasPrefix(s[i:], "https://")):
+			end := i
+			for end < len(s) && end-i <= maxMarkdownAutolink && !isMarkdownSpace(s[end]) && s[end] != '<' {
+				end++
+			}
+			if end-i > maxMarkdownAutolink {
+				break
+			}
+			for end > i && strings.IndexByte(".,;:!?)'\"", s[end-1]) >= 0 {
+				end--
+			}
+			if safe, ok := safeMarkdownURL(s[i:end]); ok {
+				flush(i)
+				out.WriteString(markdownLink(safe, html.EscapeString(s[i:end])))
+				i = end
+				text = i
+				continue
+			}
+		}
+		i++
+	}
+	flush(len(s))
+	return out.String()
+}
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const linkAttrs = `rel="nofollow noopener noreferrer" target="_blank"`
+
+func TestRenderMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"javascript", "[x](javascript:alert(1))", "<p>x</p>"},
+		{"javascript mixed case", "[x](JaVaScRiPt:alert(1))", "<p>x</p>"},
+		{"javascript padded", "[x](  javascript:alert(1)  )", "<p>x</p>"},
+		{"javascript split by tab", "[x](java\tscript:alert(1))", "<p>x</p>"},
+		{"javascript image", "![x](javascript:alert(1))", "<p>x</p>"},
+		{"data", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>"},
+		{"vbscript", "[x](vbscript:msgbox(1))", "<p>x</p>"},
+		{"relative", "[x](/tasklist)", "<p>x</p>"},
+		{"https", "[x](https://a.com/b?c=1&d=2)", `<p><a href="https://a.com/b?c=1&amp;d=2" ` + linkAttrs + `>x</a></p>`},
+		{"mailto", "[x](mailto:a@b.com)", `<p><a href="mailto:a@b.com" ` + linkAttrs + `>x</a></p>`},
+		{"title dropped", `[x](http://a.com "title")`, `<p><a href="http://a.com" ` + linkAttrs + `>x</a></p>`},
+		{"parentheses in url", "[x](http://a.com/(b))", `<p><a href="http://a.com/(b)" ` + linkAttrs + `>x</a></p>`},
+		{"nested brackets", "[a [b] c](http://a.com)", `<p><a href="http://a.com" ` + linkAttrs + `>a [b] c</a></p>`},
+		{"escaped bracket", `[a \] b](http://a.com)`, `<p><a href="http://a.com" ` + linkAttrs + `>a ] b</a></p>`},
+		{"link in label", "[[x](http://a.com)](http://b.com)", `<p><a href="http://b.com" ` + linkAttrs + `>x</a></p>`},
+		{"autolink in label", "[see https://a.com](http://b.com)", `<p><a href="http://b.com" ` + linkAttrs + `>see https://a.com</a></p>`},
+		{"unclosed label", "[[a]", "<p>[[a]</p>"},
+		{"unclosed href", "[a](http://a.com", `<p>[a](<a href="http://a.com" ` + linkAttrs + `>http://a.com</a></p>`},
+		{"autolink", "see https://a.com.", `<p>see <a href="https://a.com" ` + linkAttrs + `>https://a.com</a>.</p>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RenderMarkdown(test.src); got != test.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownEscapesHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"event handler", "<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>"},
+		{"html in label", "[<b>x</b>](http://a.com)", `<p><a href="http://a.com" ` + linkAttrs + `>&lt;b&gt;x&lt;/b&gt;</a></p>`},
+		{"quote in href", `[x](http://a.com" onclick="alert(1))`, `<p><a href="http://a.com&#34;" ` + linkAttrs + `>x</a></p>`},
+		{"quote in autolink", `https://a.com/"onmouseover="alert(1)`, `<p><a href="https://a.com/%22onmouseover=%22alert%281" ` + linkAttrs + `>https://a.com/&#34;onmouseover=&#34;alert(1</a>)</p>`},
+		{"code span", "`<script>`", "<p><code>&lt;script&gt;</code></p>"},
+		{"code block", "```\n<script>\n```", "<pre><code>&lt;script&gt;</code></pre>"},
+		{"heading", "# <i>x</i>", "<h1>&lt;i&gt;x&lt;/i&gt;</h1>"},
+		{"table cell", "| a |\n| - |\n| <b> |", "<table>\n<thead>\n<tr><th>a</th></tr>\n</thead>\n<tbody>\n<tr><td>&lt;b&gt;</td></tr>\n</tbody>\n</table>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RenderMarkdown(test.src); got != test.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownInline(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"emphasis", "**bold** and _em_ and ~~del~~", "<p><strong>bold</strong> and <em>em</em> and <del>del</del></p>"},
+		{"nested emphasis", "**a _b_ c**", "<p><strong>a <em>b</em> c</strong></p>"},
+		{"intraword underscore", "snake_case_name", "<p>snake_case_name</p>"},
+		{"unclosed emphasis", "*a *b", "<p>*a *b</p>"},
+		{"escaped", `\*a\*`, "<p>*a*</p>"},
+		{"code span", "``a ` b``", "<p><code>a ` b</code></p>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RenderMarkdown(test.src); got != test.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", test.src, got, test.want)
+			}
+		})
+	}
+}
+
+// TestRenderMarkdownPathological renders input that made the inline scans
+// quadratic. Each case takes a few milliseconds when rendering is linear.
+func TestRenderMarkdownPathological(t *testing.T) {
+	const n = 50000
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"asterisks", strings.Repeat("*", n)},
+		{"underscores", strings.Repeat("_", n)},
+		{"tildes", strings.Repeat("~", n)},
+		{"unclosed emphasis", strings.Repeat("*a ", n)},
+		{"unclosed strong", strings.Repeat("**a ", n)},
+		{"unclosed underscores", strings.Repeat("_a ", n)},
+		{"open brackets", strings.Repeat("[", n)},
+		{"unclosed links", strings.Repeat("[a](", n)},
+		{"backticks", strings.Repeat("`", n)},
+		{"unclosed code spans", strings.Repeat("`a``b", n)},
+		{"nested emphasis", strings.Repeat("*_", n) + "x" + strings.Repeat("_*", n)},
+		{"nested links", strings.Repeat("[", n) + "x" + strings.Repeat("](http://a.com)", n)},
+		{"nested quotes", strings.Repeat("> ", n) + "x"},
+		{"nested lists", strings.Repeat("- ", n) + "x"},
+		{"urls", strings.Repeat("/http://%zz", n)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			RenderMarkdown(test.src)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("rendering %d bytes took %v", len(test.src), elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxMentionName is the longest display name in the user directory, in
+// runes.
+const maxMentionName = 100
+
+// MentionCandidate is an "@" in a text that may name a user. Start counts
+// runes from the beginning of the text and points at the "@".
+type MentionCandidate struct {
+	Start int
+	Token string // the word right after the "@"
+	Rest  string // the text after the "@", as long as a name can be
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'
+}
+
+// FindMentionCandidates lists the "@word"s of text. An "@" inside a word,
+// as in an email address, is not a mention.
+func FindMentionCandidates(text string) []MentionCandidate {
+	runes := []rune(text)
+	var candidates []MentionCandidate
+	for i, r := range runes {
+		if r != '@' || (i > 0 && isMentionRune(runes[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && isMentionRune(runes[end]) {
+			end++
+		}
+		token := strings.TrimRight(string(runes[i+1:end]), ".-")
+		if token == "" {
+			continue
+		}
+		rest := runes[i+1 : min(len(runes), i+1+maxMentionName)]
+		candidates = append(candidates, MentionCandidate{Start: i, Token: token, Rest: string(rest)})
+	}
+	return candidates
+}
+
+// MatchMentionName returns the longest of names that rest starts with,
+// ignoring case, and its length in runes. The name must end at a word
+// boundary, so "@Ann" does not match "Anna".
+func MatchMentionName(rest string, names []string) (string, int) {
+	runes := []rune(rest)
+	var best string
+	var bestLen int
+	for _, name := range names {
+		length := len([]rune(name))
+		if length == 0 || length > len(runes) || length <= bestLen {
+			continue
+		}
+		if !strings.EqualFold(string(runes[:length]), name) {
+			continue
+		}
+		if length < len(runes) && (unicode.IsLetter(runes[length]) || unicode.IsDigit(runes[length])) {
+			continue
+		}
+		best, bestLen = name, length
+	}
+	return best, bestLen
+}
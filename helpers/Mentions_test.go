@@ -0,0 +1,52 @@
+package helper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindMentionCandidates(t *testing.T) {
+	long := strings.Repeat("x", 2*maxMentionName)
+	tests := []struct {
+		name string
+		text string
+		want []MentionCandidate
+	}{
+		{"number", "ping @12345.", []MentionCandidate{{Start: 5, Token: "12345", Rest: "12345."}}},
+		{"name", "@Budi Santoso, see", []MentionCandidate{{Start: 0, Token: "Budi", Rest: "Budi Santoso, see"}}},
+		{"email is not a mention", "mail a@b.com", nil},
+		{"bare at", "@ and @.", nil},
+		{"runes", "héllo @Jörg", []MentionCandidate{{Start: 6, Token: "Jörg", Rest: "Jörg"}}},
+		{"rest stops at the longest name", "@" + long, []MentionCandidate{{Start: 0, Token: long, Rest: long[:maxMentionName]}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := FindMentionCandidates(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("FindMentionCandidates(%q)\n got %+v\nwant %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchMentionName(t *testing.T) {
+	names := []string{"Ann", "Anna", "Ann Lee", "Budi Santoso"}
+	tests := []struct {
+		rest   string
+		want   string
+		length int
+	}{
+		{"Ann Lee, hi", "Ann Lee", 7},
+		{"ann lee", "Ann Lee", 7},
+		{"Anna!", "Anna", 4},
+		{"Ann Leeds", "Ann", 3},
+		{"Annie", "", 0},
+		{"budi santoso", "Budi Santoso", 12},
+	}
+	for _, test := range tests {
+		name, length := MatchMentionName(test.rest, names)
+		if name != test.want || length != test.length {
+			t.Errorf("MatchMentionName(%q) = %q, %d, want %q, %d", test.rest, name, length, test.want, test.length)
+		}
+	}
+}
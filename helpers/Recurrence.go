@@ -0,0 +1,751 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is a parsed scheduler rule. Generate_Every accepts either a
+// 5-field cron expression ("0 8 * * MON,WED,FRI"), an RRULE
+// ("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1") or one of the legacy
+// keywords DAILY, WEEKLY, MONTHLY and YEARLY.
+type Recurrence interface {
+	// Next returns the first occurrence strictly after the given time.
+	// The boolean is false when the rule has no further occurrences.
+	Next(after time.Time) (time.Time, bool)
+	// Describe returns the rule in readable form.
+	Describe() string
+	// Limit returns the rule's COUNT, or 0 when it does not end after a
+	// number of occurrences.
+	Limit() int
+}
+
+// maxRecurrenceScan bounds the number of days or periods walked while
+// looking for the next occurrence, so impossible rules terminate.
+const maxRecurrenceScan = 366 * 10
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var legacyFrequencies = map[string]string{
+	"DAILY": "DAILY", "DAY": "DAILY",
+	"WEEKLY": "WEEKLY", "WEEK": "WEEKLY",
+	"MONTHLY": "MONTHLY", "MONTH": "MONTHLY",
+	"YEARLY": "YEARLY", "YEAR": "YEARLY",
+}
+
+var weekdayShort = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// ParseRecurrence parses a Generate_Every value. Rules without an explicit
+// DTSTART are anchored at start.
+func ParseRecurrence(expr string, start time.Time) (Recurrence, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("recurrence rule is empty")
+	}
+	upper := strings.ToUpper(expr)
+	if freq, ok := legacyFrequencies[upper]; ok {
+		return parseRRule("FREQ="+freq, start)
+	}
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return parseRRule(expr, start)
+	}
+	return parseCron(expr)
+}
+
+// PreviewRecurrence returns up to n occurrences after the given time.
+func PreviewRecurrence(rule Recurrence, after time.Time, n int) []time.Time {
+	occurrences := make([]time.Time, 0, n)
+	for len(occurrences) < n {
+		next, ok := rule.Next(after)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+		after = next
+	}
+	return occurrences
+}
+
+// DescribeRecurrence returns the readable form of a Generate_Every value,
+// falling back to the raw text when it cannot be parsed.
+func DescribeRecurrence(expr string) string {
+	rule, err := ParseRecurrence(expr, time.Now())
+	if err != nil {
+		return expr
+	}
+	return rule.Describe()
+}
+
+// ---------------------------------------------------------------------------
+// Cron
+// ---------------------------------------------------------------------------
+
+type cronRule struct {
+	minutes  []int
+	hours    []int
+	days     []int
+	months   []int
+	weekdays []int
+	lastDay  bool // "L" in the day-of-month field
+	anyDay   bool
+	anyWeek  bool
+	expr     string
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+func parseCron(expr string) (Recurrence, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+	rule := &cronRule{expr: strings.Join(fields, " ")}
+	var err error
+	if rule.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %v", err)
+	}
+	if rule.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %v", err)
+	}
+	dom := strings.ToUpper(fields[2])
+	if dom == "L" {
+		rule.lastDay = true
+	} else if rule.days, err = parseCronField(dom, 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %v", err)
+	}
+	if rule.months, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("month: %v", err)
+	}
+	if rule.weekdays, err = parseCronField(fields[4], 0, 7, cronWeekdayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %v", err)
+	}
+	for i, d := range rule.weekdays {
+		if d == 7 {
+			rule.weekdays[i] = 0
+		}
+	}
+	rule.weekdays = uniqueSorted(rule.weekdays)
+	rule.anyDay = dom == "*" || dom == "?"
+	rule.anyWeek = fields[4] == "*" || fields[4] == "?"
+	return rule, nil
+}
+
+func parseCronField(field string, min, max int, names map[string]int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(strings.ToUpper(field), ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			step = s
+			part = part[:idx]
+		}
+		lo, hi := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], names); err != nil {
+				return nil, err
+			}
+			if hi, err = cronValue(bounds[1], names); err != nil {
+				return nil, err
+			}
+		default:
+			v, err := cronValue(part, names)
+			if err != nil {
+				return nil, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("value out of range %d-%d in %q", min, max, field)
+		}
+		for v := lo; v <= hi; v += step {
+			values = append(values, v)
+		}
+	}
+	return uniqueSorted(values), nil
+}
+
+func cronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+func (r *cronRule) matchesDay(day time.Time) bool {
+	if !containsInt(r.months, int(day.Month())) {
+		return false
+	}
+	domMatch := containsInt(r.days, day.Day())
+	if r.lastDay {
+		domMatch = day.AddDate(0, 0, 1).Day() == 1
+	}
+	dowMatch := containsInt(r.weekdays, int(day.Weekday()))
+	// Standard cron: when both fields are restricted either may match.
+	switch {
+	case r.anyDay && r.anyWeek:
+		return true
+	case r.anyDay:
+		return dowMatch
+	case r.anyWeek:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+func (r *cronRule) Next(after time.Time) (time.Time, bool) {
+	after = after.Truncate(time.Minute)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, after.Location())
+	for i := 0; i < maxRecurrenceScan; i++ {
+		if r.matchesDay(day) {
+			for _, h := range r.hours {
+				for _, m := range r.minutes {
+					t := wallTime(day, h, m)
+					if t.After(after) {
+						return t, true
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
+
+func (r *cronRule) Limit() int {
+	return 0
+}
+
+func (r *cronRule) Describe() string {
+	var parts []string
+	if len(r.hours) == 1 && len(r.minutes) == 1 {
+		parts = append(parts, fmt.Sprintf("at %02d:%02d", r.hours[0], r.minutes[0]))
+	} else if len(r.hours) == 24 && len(r.minutes) == 60 {
+		parts = append(parts, "every minute")
+	} else if len(r.hours) == 24 {
+		parts = append(parts, "at minute "+joinInts(r.minutes)+" of every hour")
+	} else {
+		parts = append(parts, "at minute "+joinInts(r.minutes)+" past hour "+joinInts(r.hours))
+	}
+	var days []string
+	if r.lastDay {
+		days = append(days, "on the last day of the month")
+	} else if !r.anyDay {
+		days = append(days, "on day "+joinInts(r.days)+" of the month")
+	}
+	if !r.anyWeek {
+		names := make([]string, len(r.weekdays))
+		for i, d := range r.weekdays {
+			names[i] = weekdayShort[d]
+		}
+		days = append(days, "on "+strings.Join(names, ", "))
+	}
+	if len(days) > 0 {
+		parts = append(parts, strings.Join(days, " or "))
+	} else {
+		parts = append(parts, "every day")
+	}
+	if len(r.months) < 12 {
+		names := make([]string, len(r.months))
+		for i, m := range r.months {
+			names[i] = time.Month(m).String()[:3]
+		}
+		parts = append(parts, "in "+strings.Join(names, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ---------------------------------------------------------------------------
+// RRULE
+// ---------------------------------------------------------------------------
+
+type byDay struct {
+	ordinal int // 0 means every such weekday in the period
+	weekday time.Weekday
+}
+
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []byDay
+	byMonthDay []int
+	byMonth    []int
+	byHour     []int
+	byMinute   []int
+	bySetPos   []int
+	dtstart    time.Time
+}
+
+func parseRRule(expr string, start time.Time) (Recurrence, error) {
+	rule := &rrule{interval: 1, dtstart: start.Truncate(time.Minute)}
+	expr = strings.TrimSpace(expr)
+	if len(expr) >= 6 && strings.EqualFold(expr[:6], "RRULE:") {
+		expr = expr[6:]
+	}
+	for _, part := range strings.Split(expr, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(strings.TrimSpace(kv[1]))
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.freq = value
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			if rule.interval, err = strconv.Atoi(value); err != nil || rule.interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+		case "COUNT":
+			if rule.count, err = strconv.Atoi(value); err != nil || rule.count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+		case "UNTIL":
+			if rule.until, err = parseRRuleTime(value, start.Location()); err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+		case "DTSTART":
+			if rule.dtstart, err = parseRRuleTime(value, start.Location()); err != nil {
+				return nil, fmt.Errorf("invalid DTSTART %q", value)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				if len(d) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %q", d)
+				}
+				wd, ok := weekdayCodes[d[len(d)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", d)
+				}
+				entry := byDay{weekday: wd}
+				if prefix := d[:len(d)-2]; prefix != "" {
+					if entry.ordinal, err = strconv.Atoi(prefix); err != nil || entry.ordinal == 0 {
+						return nil, fmt.Errorf("invalid BYDAY %q", d)
+					}
+				}
+				rule.byDay = append(rule.byDay, entry)
+			}
+		case "BYMONTHDAY":
+			if rule.byMonthDay, err = parseIntList(value, -31, 31); err != nil {
+				return nil, fmt.Errorf("invalid BYMONTHDAY: %v", err)
+			}
+		case "BYMONTH":
+			if rule.byMonth, err = parseIntList(value, 1, 12); err != nil {
+				return nil, fmt.Errorf("invalid BYMONTH: %v", err)
+			}
+		case "BYHOUR":
+			if rule.byHour, err = parseIntList(value, 0, 23); err != nil {
+				return nil, fmt.Errorf("invalid BYHOUR: %v", err)
+			}
+		case "BYMINUTE":
+			if rule.byMinute, err = parseIntList(value, 0, 59); err != nil {
+				return nil, fmt.Errorf("invalid BYMINUTE: %v", err)
+			}
+		case "BYSETPOS":
+			if rule.bySetPos, err = parseIntList(value, -366, 366); err != nil {
+				return nil, fmt.Errorf("invalid BYSETPOS: %v", err)
+			}
+		case "WKST":
+			// Weeks always start on Monday.
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+	if rule.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.count > 0 && !rule.until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	return rule, nil
+}
+
+func parseRRuleTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			if strings.HasSuffix(value, "Z") {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC).In(loc)
+			}
+			if layout == "20060102" || layout == "2006-01-02" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time")
+}
+
+func (r *rrule) Next(after time.Time) (time.Time, bool) {
+	seen := 0
+	for period := 0; period < maxRecurrenceScan; period++ {
+		if !r.until.IsZero() && r.periodStart(period).After(r.until) {
+			return time.Time{}, false
+		}
+		for _, t := range r.expand(period) {
+			if t.Before(r.dtstart) {
+				continue
+			}
+			if !r.until.IsZero() && t.After(r.until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.count > 0 && seen > r.count {
+				return time.Time{}, false
+			}
+			if t.After(after) {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *rrule) periodStart(period int) time.Time {
+	s := r.dtstart
+	base := time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, s.Location())
+	switch r.freq {
+	case "DAILY":
+		return base.AddDate(0, 0, period*r.interval)
+	case "WEEKLY":
+		offset := (int(base.Weekday()) + 6) % 7
+		return base.AddDate(0, 0, -offset+7*period*r.interval)
+	case "MONTHLY":
+		return time.Date(s.Year(), s.Month()+time.Month(period*r.interval), 1, 0, 0, 0, 0, s.Location())
+	default:
+		return time.Date(s.Year()+period*r.interval, 1, 1, 0, 0, 0, 0, s.Location())
+	}
+}
+
+// expand returns the sorted occurrences falling in the given period.
+func (r *rrule) expand(period int) []time.Time {
+	start := r.periodStart(period)
+	var days []time.Time
+	switch r.freq {
+	case "DAILY":
+		days = []time.Time{start}
+	case "WEEKLY":
+		for i := 0; i < 7; i++ {
+			d := start.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && len(r.byMonthDay) == 0 {
+				if d.Weekday() == r.dtstart.Weekday() {
+					days = append(days, d)
+				}
+				continue
+			}
+			days = append(days, d)
+		}
+	case "MONTHLY":
+		days = r.monthDays(start)
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []int{int(r.dtstart.Month())}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(time.Date(start.Year(), time.Month(m), 1, 0, 0, 0, 0, start.Location()))...)
+		}
+	}
+
+	var filtered []time.Time
+	for _, d := range days {
+		if len(r.byMonth) > 0 && !containsInt(r.byMonth, int(d.Month())) {
+			continue
+		}
+		if r.freq == "DAILY" || r.freq == "WEEKLY" {
+			if len(r.byDay) > 0 && !r.matchesPlainWeekday(d) {
+				continue
+			}
+			if len(r.byMonthDay) > 0 && !matchesMonthDay(r.byMonthDay, d) {
+				continue
+			}
+		}
+		filtered = append(filtered, d)
+	}
+
+	hours, minutes := r.byHour, r.byMinute
+	if len(hours) == 0 {
+		hours = []int{r.dtstart.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{r.dtstart.Minute()}
+	}
+	var out []time.Time
+	for _, d := range filtered {
+		for _, h := range hours {
+			for _, m := range minutes {
+				out = append(out, wallTime(d, h, m))
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return applySetPos(out, r.bySetPos)
+}
+
+// monthDays expands BYMONTHDAY/BYDAY within the month starting at first.
+func (r *rrule) monthDays(first time.Time) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for day := 1; day <= last; day++ {
+		d := time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, first.Location())
+		switch {
+		case len(r.byMonthDay) > 0 && len(r.byDay) > 0:
+			if matchesMonthDay(r.byMonthDay, d) && r.matchesOrdinalWeekday(d) {
+				days = append(days, d)
+			}
+		case len(r.byMonthDay) > 0:
+			if matchesMonthDay(r.byMonthDay, d) {
+				days = append(days, d)
+			}
+		case len(r.byDay) > 0:
+			if r.matchesOrdinalWeekday(d) {
+				days = append(days, d)
+			}
+		default:
+			if day == r.dtstart.Day() {
+				days = append(days, d)
+			}
+		}
+	}
+	return days
+}
+
+func (r *rrule) matchesPlainWeekday(d time.Time) bool {
+	for _, bd := range r.byDay {
+		if bd.weekday == d.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rrule) matchesOrdinalWeekday(d time.Time) bool {
+	last := d.AddDate(0, 1, -d.Day()).Day()
+	for _, bd := range r.byDay {
+		if bd.weekday != d.Weekday() {
+			continue
+		}
+		switch {
+		case bd.ordinal == 0:
+			return true
+		case bd.ordinal > 0 && (d.Day()-1)/7+1 == bd.ordinal:
+			return true
+		case bd.ordinal < 0 && (last-d.Day())/7+1 == -bd.ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(days []int, d time.Time) bool {
+	last := d.AddDate(0, 1, -d.Day()).Day()
+	for _, md := range days {
+		if md > 0 && d.Day() == md {
+			return true
+		}
+		if md < 0 && d.Day() == last+md+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func applySetPos(set []time.Time, positions []int) []time.Time {
+	if len(positions) == 0 || len(set) == 0 {
+		return set
+	}
+	var out []time.Time
+	for _, p := range positions {
+		idx := p - 1
+		if p < 0 {
+			idx = len(set) + p
+		}
+		if idx >= 0 && idx < len(set) {
+			out = append(out, set[idx])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (r *rrule) Limit() int {
+	return r.count
+}
+
+func (r *rrule) Describe() string {
+	units := map[string]string{"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year"}
+	var b strings.Builder
+	if r.interval == 1 {
+		b.WriteString("every " + units[r.freq])
+	} else {
+		fmt.Fprintf(&b, "every %d %ss", r.interval, units[r.freq])
+	}
+	if len(r.byMonth) > 0 {
+		names := make([]string, len(r.byMonth))
+		for i, m := range r.byMonth {
+			names[i] = time.Month(m).String()
+		}
+		b.WriteString(" in " + strings.Join(names, ", "))
+	}
+
+	var days []string
+	for _, bd := range r.byDay {
+		name := weekdayShort[bd.weekday]
+		if bd.ordinal != 0 {
+			name = ordinalWord(bd.ordinal) + " " + name
+		}
+		days = append(days, name)
+	}
+	var monthDays []string
+	for _, md := range r.byMonthDay {
+		if md == -1 {
+			monthDays = append(monthDays, "the last day")
+		} else if md < 0 {
+			monthDays = append(monthDays, fmt.Sprintf("%d days before the end", -md-1))
+		} else {
+			monthDays = append(monthDays, "day "+strconv.Itoa(md))
+		}
+	}
+	target := strings.Join(append(monthDays, days...), ", ")
+	if len(r.bySetPos) > 0 && target != "" {
+		var pos []string
+		for _, p := range r.bySetPos {
+			pos = append(pos, ordinalWord(p))
+		}
+		b.WriteString(" on the " + strings.Join(pos, " and ") + " of " + target)
+	} else if target != "" {
+		b.WriteString(" on " + target)
+	}
+
+	hours, minutes := r.byHour, r.byMinute
+	if len(hours) == 0 {
+		hours = []int{r.dtstart.Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{r.dtstart.Minute()}
+	}
+	var times []string
+	for _, h := range hours {
+		for _, m := range minutes {
+			times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+		}
+	}
+	b.WriteString(" at " + strings.Join(times, ", "))
+
+	if r.count > 0 {
+		fmt.Fprintf(&b, ", %d times", r.count)
+	}
+	if !r.until.IsZero() {
+		b.WriteString(", until " + r.until.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+func ordinalWord(n int) string {
+	words := map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", 5: "fifth", -1: "last", -2: "second to last", -3: "third to last"}
+	if w, ok := words[n]; ok {
+		return w
+	}
+	if n < 0 {
+		return fmt.Sprintf("%d from last", -n)
+	}
+	return fmt.Sprintf("%dth", n)
+}
+
+// ---------------------------------------------------------------------------
+// Shared helpers
+// ---------------------------------------------------------------------------
+
+// wallTime returns hour:minute on the day of day in its location. A time
+// skipped when clocks go forward is moved past the gap, as cron does, since
+// time.Date may resolve it to either side.
+func wallTime(day time.Time, hour, minute int) time.Time {
+	t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	if t.Hour() == hour && t.Minute() == minute {
+		return t
+	}
+	_, offset := t.Zone()
+	shifted := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.UTC).
+		Add(-time.Duration(offset) * time.Second).In(day.Location())
+	if shifted.After(t) {
+		return shifted
+	}
+	return t
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var out []int
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || v < min || v > max || v == 0 && min < 0 {
+			return nil, fmt.Errorf("value %q out of range", s)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,283 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+const occurrenceLayout = "2006-01-02 15:04 MST"
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func formatOccurrences(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, at := range times {
+		out[i] = at.Format(occurrenceLayout)
+	}
+	return out
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	newYork := mustLocation(t, "America/New_York")
+	tests := []struct {
+		name  string
+		expr  string
+		start time.Time
+		after time.Time
+		n     int
+		want  []string
+		limit int
+	}{
+		{
+			name:  "cron weekdays",
+			expr:  "0 8 * * MON,WED,FRI",
+			after: time.Date(2026, 10, 19, 9, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-10-21 08:00 WIB", "2026-10-23 08:00 WIB", "2026-10-26 08:00 WIB"},
+		},
+		{
+			name:  "cron steps",
+			expr:  "*/15 * * * *",
+			after: time.Date(2026, 10, 19, 10, 7, 30, 0, jakarta),
+			n:     2,
+			want:  []string{"2026-10-19 10:15 WIB", "2026-10-19 10:30 WIB"},
+		},
+		{
+			name:  "cron last day of month",
+			expr:  "30 9 L * *",
+			after: time.Date(2026, 1, 31, 10, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-02-28 09:30 WIB", "2026-03-31 09:30 WIB", "2026-04-30 09:30 WIB"},
+		},
+		{
+			name:  "cron day 31 skips short months",
+			expr:  "0 7 31 * *",
+			after: time.Date(2026, 1, 31, 8, 0, 0, 0, jakarta),
+			n:     2,
+			want:  []string{"2026-03-31 07:00 WIB", "2026-05-31 07:00 WIB"},
+		},
+		{
+			name:  "cron leap day",
+			expr:  "0 0 29 2 *",
+			after: time.Date(2026, 3, 1, 0, 0, 0, 0, jakarta),
+			n:     1,
+			want:  []string{"2028-02-29 00:00 WIB"},
+		},
+		{
+			name:  "cron day of month or weekday",
+			expr:  "0 9 1 * SUN",
+			after: time.Date(2026, 10, 30, 0, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-11-01 09:00 WIB", "2026-11-08 09:00 WIB", "2026-11-15 09:00 WIB"},
+		},
+		{
+			name:  "cron across spring forward",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 7, 3, 0, 0, 0, newYork),
+			n:     2,
+			want:  []string{"2026-03-08 03:30 EDT", "2026-03-09 02:30 EDT"},
+		},
+		{
+			name:  "cron once on fall back",
+			expr:  "30 1 * * *",
+			after: time.Date(2026, 10, 31, 12, 0, 0, 0, newYork),
+			n:     3,
+			want:  []string{"2026-11-01 01:30 EDT", "2026-11-02 01:30 EST", "2026-11-03 01:30 EST"},
+		},
+		{
+			name:  "legacy keyword keeps the day of month",
+			expr:  "monthly",
+			start: time.Date(2026, 1, 31, 9, 0, 0, 0, jakarta),
+			after: time.Date(2026, 1, 31, 8, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-01-31 09:00 WIB", "2026-03-31 09:00 WIB", "2026-05-31 09:00 WIB"},
+		},
+		{
+			name:  "legacy weekly",
+			expr:  "WEEK",
+			start: time.Date(2026, 10, 21, 14, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 21, 14, 0, 0, 0, jakarta),
+			n:     2,
+			want:  []string{"2026-10-28 14:00 WIB", "2026-11-04 14:00 WIB"},
+		},
+		{
+			name:  "rrule last weekday of the month",
+			expr:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: time.Date(2026, 1, 1, 17, 0, 0, 0, jakarta),
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-01-30 17:00 WIB", "2026-02-27 17:00 WIB", "2026-03-31 17:00 WIB"},
+		},
+		{
+			name:  "rrule last day of the month",
+			expr:  "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18;BYMINUTE=0",
+			start: time.Date(2026, 1, 1, 9, 0, 0, 0, jakarta),
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta),
+			n:     3,
+			want:  []string{"2026-01-31 18:00 WIB", "2026-02-28 18:00 WIB", "2026-03-31 18:00 WIB"},
+		},
+		{
+			name:  "rrule second tuesday",
+			expr:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: time.Date(2026, 10, 1, 10, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 1, 0, 0, 0, 0, jakarta),
+			n:     2,
+			want:  []string{"2026-10-13 10:00 WIB", "2026-11-10 10:00 WIB"},
+		},
+		{
+			name:  "rrule every other week",
+			expr:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta),
+			n:     4,
+			want:  []string{"2026-10-19 08:00 WIB", "2026-10-22 08:00 WIB", "2026-11-02 08:00 WIB", "2026-11-05 08:00 WIB"},
+		},
+		{
+			name:  "rrule leap day",
+			expr:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			start: time.Date(2026, 1, 1, 6, 0, 0, 0, jakarta),
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta),
+			n:     2,
+			want:  []string{"2028-02-29 06:00 WIB", "2032-02-29 06:00 WIB"},
+		},
+		{
+			name:  "rrule count",
+			expr:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta),
+			n:     5,
+			want:  []string{"2026-10-19 08:00 WIB", "2026-10-20 08:00 WIB", "2026-10-21 08:00 WIB"},
+			limit: 3,
+		},
+		{
+			name:  "rrule count counts from dtstart",
+			expr:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 20, 12, 0, 0, 0, jakarta),
+			n:     5,
+			want:  []string{"2026-10-21 08:00 WIB"},
+			limit: 3,
+		},
+		{
+			name:  "rrule until a date includes that day",
+			expr:  "FREQ=WEEKLY;UNTIL=20261102",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta),
+			n:     5,
+			want:  []string{"2026-10-19 08:00 WIB", "2026-10-26 08:00 WIB", "2026-11-02 08:00 WIB"},
+		},
+		{
+			name:  "rrule until in utc",
+			expr:  "FREQ=DAILY;UNTIL=20261020T010000Z",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta),
+			n:     5,
+			want:  []string{"2026-10-19 08:00 WIB", "2026-10-20 08:00 WIB"},
+		},
+		{
+			name:  "rrule dtstart overrides start",
+			expr:  "FREQ=DAILY;DTSTART=20261101T093000",
+			start: time.Date(2026, 10, 19, 8, 0, 0, 0, jakarta),
+			after: time.Date(2026, 10, 19, 0, 0, 0, 0, jakarta),
+			n:     2,
+			want:  []string{"2026-11-01 09:30 WIB", "2026-11-02 09:30 WIB"},
+		},
+		{
+			name:  "rrule in the skipped hour",
+			expr:  "FREQ=DAILY;BYHOUR=2;BYMINUTE=30",
+			start: time.Date(2026, 3, 7, 9, 0, 0, 0, newYork),
+			after: time.Date(2026, 3, 7, 0, 0, 0, 0, newYork),
+			n:     3,
+			want:  []string{"2026-03-08 03:30 EDT", "2026-03-09 02:30 EDT", "2026-03-10 02:30 EDT"},
+		},
+		{
+			name:  "rrule across spring forward",
+			expr:  "FREQ=DAILY",
+			start: time.Date(2026, 3, 7, 9, 0, 0, 0, newYork),
+			after: time.Date(2026, 3, 7, 0, 0, 0, 0, newYork),
+			n:     3,
+			want:  []string{"2026-03-07 09:00 EST", "2026-03-08 09:00 EDT", "2026-03-09 09:00 EDT"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := test.start
+			if start.IsZero() {
+				start = test.after
+			}
+			rule, err := ParseRecurrence(test.expr, start)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q): %v", test.expr, err)
+			}
+			got := formatOccurrences(PreviewRecurrence(rule, test.after, test.n))
+			if strings.Join(got, ", ") != strings.Join(test.want, ", ") {
+				t.Errorf("occurrences after %s\n got %v\nwant %v", test.after.Format(occurrenceLayout), got, test.want)
+			}
+			if rule.Limit() != test.limit {
+				t.Errorf("Limit() = %d, want %d", rule.Limit(), test.limit)
+			}
+		})
+	}
+}
+
+func TestRecurrenceEnds(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		last time.Time
+	}{
+		{"FREQ=DAILY;COUNT=2", time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC)},
+		{"FREQ=DAILY;UNTIL=20261020", time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", start},
+	}
+	for _, test := range tests {
+		rule, err := ParseRecurrence(test.expr, start)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", test.expr, err)
+		}
+		if next, ok := rule.Next(test.last); ok {
+			t.Errorf("%q: Next(%v) = %v, want no further occurrence", test.expr, test.last, next)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	for _, expr := range []string{
+		"",
+		"   ",
+		"0 8 * *",
+		"0 8 * * * *",
+		"61 * * * *",
+		"0 24 * * *",
+		"0 8 0 * *",
+		"0 8 * 13 *",
+		"0 8 * * FUNDAY",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20261101",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYSECOND=1",
+		"INTERVAL=2",
+	} {
+		if _, err := ParseRecurrence(expr, start); err == nil {
+			t.Errorf("ParseRecurrence(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package helper
+
+import "time"
+
+const (
+	SLAStatusOnTrack = "ON_TRACK"
+	SLAStatusWarning = "WARNING"
+	SLAStatusOutdate = "OUTDATE"
+	SLAStatusMet     = "MET"
+)
+
+// SLATarget holds the targets of one SLA policy.
+type SLATarget struct {
+	Response       time.Duration
+	Resolution     time.Duration
+	WarningPercent int
+	// WorkingHours counts the targets in working time of the calendar
+	// instead of wall-clock time.
+	WorkingHours bool
+}
+
+// SLAResult is the evaluated SLA state of one task.
+type SLAResult struct {
+	ResponseDue        time.Time
+	ResolutionDue      time.Time
+	WarningAt          time.Time
+	Status             string
+	ResponseBreached   bool
+	ResolutionBreached bool
+}
+
+// EvaluateSLA computes the due dates and breach state of a task created at
+// created. respondedAt and resolvedAt are nil while the task has not been
+// picked up or finished yet.
+func EvaluateSLA(target SLATarget, cal *BusinessCalendar, created time.Time, respondedAt, resolvedAt *time.Time, now time.Time) SLAResult {
+	add := func(d time.Duration) time.Time {
+		if target.WorkingHours && cal != nil {
+			return cal.AddWorkingDuration(created, d)
+		}
+		return created.Add(d)
+	}
+	warningPercent := target.WarningPercent
+	if warningPercent <= 0 || warningPercent > 100 {
+		warningPercent = 80
+	}
+	result := SLAResult{
+		ResponseDue:   add(target.Response),
+		ResolutionDue: add(target.Resolution),
+		WarningAt:     add(target.Resolution * time.Duration(warningPercent) / 100),
+		Status:        SLAStatusOnTrack,
+	}
+
+	if target.Response > 0 {
+		respondedBy := now
+		if respondedAt != nil {
+			respondedBy = *respondedAt
+		}
+		result.ResponseBreached = respondedBy.After(result.ResponseDue)
+	}
+	if target.Resolution <= 0 {
+		return result
+	}
+	resolvedBy := now
+	if resolvedAt != nil {
+		resolvedBy = *resolvedAt
+	}
+	result.ResolutionBreached = resolvedBy.After(result.ResolutionDue)
+	switch {
+	case result.ResolutionBreached:
+		result.Status = SLAStatusOutdate
+	case resolvedAt != nil:
+		result.Status = SLAStatusMet
+	case !now.Before(result.WarningAt):
+		result.Status = SLAStatusWarning
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package helper
+
+import (
+	"testing"
+	"time"
+)
+
+// setLocal makes loc the server's zone for the rest of the test.
+func setLocal(t *testing.T, loc *time.Location) {
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func TestLocalWallClock(t *testing.T) {
+	setLocal(t, mustLocation(t, "Asia/Jakarta"))
+	scanned := time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC)
+	got := LocalWallClock(scanned)
+	if want := "2026-10-19 16:00 WIB"; got.Format(occurrenceLayout) != want {
+		t.Errorf("LocalWallClock = %s, want %s", got.Format(occurrenceLayout), want)
+	}
+	if !LocalWallClock(time.Time{}).IsZero() {
+		t.Error("LocalWallClock changed the zero time")
+	}
+}
+
+func TestEvaluateSLA(t *testing.T) {
+	cal, jakarta := testCalendar(t)
+	setLocal(t, jakarta)
+	// Timestamps as the driver scans them: the local wall clock labelled UTC.
+	scanned := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+	working := SLATarget{Response: 30 * time.Minute, Resolution: 4 * time.Hour, WarningPercent: 50, WorkingHours: true}
+
+	tests := []struct {
+		name          string
+		target        SLATarget
+		created       time.Time
+		responded     *time.Time
+		resolved      *time.Time
+		now           time.Time
+		responseDue   string
+		resolutionDue string
+		warningAt     string
+		status        string
+		responseLate  bool
+	}{
+		{
+			name:          "on track",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			responded:     ptr(scanned(19, 16, 10)),
+			now:           scanned(19, 17, 30),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusOnTrack,
+		},
+		{
+			name:          "late response",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			now:           scanned(19, 16, 45),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusOnTrack,
+			responseLate:  true,
+		},
+		{
+			name:          "warning",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			responded:     ptr(scanned(19, 16, 10)),
+			now:           scanned(20, 9, 30),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusWarning,
+		},
+		{
+			name:          "outdate",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			responded:     ptr(scanned(19, 16, 10)),
+			now:           scanned(20, 11, 1),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusOutdate,
+		},
+		{
+			name:          "met",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			responded:     ptr(scanned(19, 16, 10)),
+			resolved:      ptr(scanned(20, 10, 0)),
+			now:           scanned(22, 9, 0),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusMet,
+		},
+		{
+			name:          "resolved late",
+			target:        working,
+			created:       scanned(19, 16, 0),
+			responded:     ptr(scanned(19, 16, 10)),
+			resolved:      ptr(scanned(20, 12, 0)),
+			now:           scanned(22, 9, 0),
+			responseDue:   "2026-10-19 16:30 WIB",
+			resolutionDue: "2026-10-20 11:00 WIB",
+			warningAt:     "2026-10-20 09:00 WIB",
+			status:        SLAStatusOutdate,
+		},
+		{
+			name:          "over a holiday",
+			target:        SLATarget{Resolution: 2 * time.Hour, WorkingHours: true},
+			created:       scanned(20, 16, 0),
+			now:           scanned(21, 12, 0),
+			responseDue:   "2026-10-20 16:00 WIB",
+			resolutionDue: "2026-10-22 09:00 WIB",
+			warningAt:     "2026-10-22 08:36 WIB",
+			status:        SLAStatusOnTrack,
+		},
+		{
+			name:          "wall clock",
+			target:        SLATarget{Resolution: 4 * time.Hour, WarningPercent: 50},
+			created:       scanned(19, 16, 0),
+			now:           scanned(19, 18, 30),
+			responseDue:   "2026-10-19 16:00 WIB",
+			resolutionDue: "2026-10-19 20:00 WIB",
+			warningAt:     "2026-10-19 18:00 WIB",
+			status:        SLAStatusWarning,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			created, now := test.created, test.now
+			LocalWallClocks(&created, test.responded, test.resolved, &now)
+			got := EvaluateSLA(test.target, cal, created, test.responded, test.resolved, now)
+			for _, check := range []struct {
+				field     string
+				got, want string
+			}{
+				{"ResponseDue", got.ResponseDue.Format(occurrenceLayout), test.responseDue},
+				{"ResolutionDue", got.ResolutionDue.Format(occurrenceLayout), test.resolutionDue},
+				{"WarningAt", got.WarningAt.Format(occurrenceLayout), test.warningAt},
+				{"Status", got.Status, test.status},
+			} {
+				if check.got != check.want {
+					t.Errorf("%s = %s, want %s", check.field, check.got, check.want)
+				}
+			}
+			if got.ResponseBreached != test.responseLate {
+				t.Errorf("ResponseBreached = %v, want %v", got.ResponseBreached, test.responseLate)
+			}
+			if got.ResolutionBreached != (test.status == SLAStatusOutdate) {
+				t.Errorf("ResolutionBreached = %v with status %s", got.ResolutionBreached, got.Status)
+			}
+		})
+	}
+}
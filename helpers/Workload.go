@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkloadTask is an open task counted in an employee's workload.
+type WorkloadTask struct {
+	Priority string
+	Due      *time.Time
+}
+
+// WorkloadInput collects the signals used to rank a candidate assignee.
+type WorkloadInput struct {
+	New, Open, InProgress, Hold, Warning, Outdate int64
+	Tasks                                         []WorkloadTask
+	EffortHours                                   float64
+	OutOfOfficeUntil                              *time.Time
+}
+
+// PriorityWeight maps a task priority to its weight in the workload score.
+func PriorityWeight(priority string) float64 {
+	p := strings.ToUpper(priority)
+	switch {
+	case strings.Contains(p, "URGENT"), strings.Contains(p, "CRITICAL"), strings.Contains(p, "HIGH"), strings.Contains(p, "TINGGI"):
+		return 3
+	case strings.Contains(p, "MEDIUM"), strings.Contains(p, "NORMAL"), strings.Contains(p, "SEDANG"):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// ScoreWorkload returns the workload score of a candidate (lower is less
+// busy) with one explanation line per signal that contributed.
+func ScoreWorkload(in WorkloadInput, now time.Time) (float64, []string) {
+	var score float64
+	var why []string
+
+	open := in.New + in.Open + in.InProgress + in.Hold + in.Warning + in.Outdate
+	score += float64(in.New+in.Open) + 1.5*float64(in.InProgress) + 0.5*float64(in.Hold) +
+		2*float64(in.Warning) + 2.5*float64(in.Outdate)
+	if open == 0 {
+		why = append(why, "no open tasks")
+	} else {
+		why = append(why, fmt.Sprintf("%d open tasks (%d new/open, %d in progress, %d on hold, %d warning, %d outdated)",
+			open, in.New+in.Open, in.InProgress, in.Hold, in.Warning, in.Outdate))
+	}
+
+	var highPriority, dueSoon, overdue int
+	for _, task := range in.Tasks {
+		weight := PriorityWeight(task.Priority)
+		if weight >= 3 {
+			highPriority++
+		}
+		factor := 1.0
+		if task.Due != nil {
+			switch {
+			case task.Due.Before(now):
+				factor = 2
+				overdue++
+			case task.Due.Before(now.Add(48 * time.Hour)):
+				factor = 1.5
+				dueSoon++
+			}
+		}
+		score += weight * factor
+	}
+	if highPriority > 0 {
+		why = append(why, fmt.Sprintf("%d high-priority tasks", highPriority))
+	}
+	if dueSoon > 0 {
+		why = append(why, fmt.Sprintf("%d tasks due within 2 days", dueSoon))
+	}
+	if overdue > 0 {
+		why = append(why, fmt.Sprintf("%d tasks past their due date", overdue))
+	}
+
+	if in.EffortHours > 0 {
+		score += in.EffortHours / 8
+		why = append(why, fmt.Sprintf("logged %.1f hours in the last 7 days", in.EffortHours))
+	}
+	if in.OutOfOfficeUntil != nil {
+		why = append(why, "out of office until "+in.OutOfOfficeUntil.Format("2006-01-02"))
+	}
+	return score, why
+}
@@ -37,3 +37,34 @@ func FormatLocalDate(format, date string) string {
 	}
 	return tm2
 }
+
+// ParseLocalTimestamp parses a timestamp without time zone scanned into a
+// string. The driver labels its wall clock as UTC, so the clock is read back
+// in the server's zone, the one the database writes it in.
+func ParseLocalTimestamp(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	}
+	return LocalWallClock(t), nil
+}
+
+// LocalWallClock reads the wall clock of t in the server's zone. Timestamps
+// without time zone come back from the driver labelled UTC, while the
+// database holds them as the server's local time.
+func LocalWallClock(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+}
+
+// LocalWallClocks applies LocalWallClock to each time in place, skipping
+// nil ones.
+func LocalWallClocks(times ...*time.Time) {
+	for _, t := range times {
+		if t != nil {
+			*t = LocalWallClock(*t)
+		}
+	}
+}
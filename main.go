@@ -5,6 +5,7 @@ import (
 	"go-todolist/controllers"
 	"go-todolist/cors"
 	"go-todolist/docs"
+	helper "go-todolist/helpers"
 	"io"
 	"net/http"
 	"os"
@@ -73,6 +74,25 @@ func setupRouter() *gin.Engine {
 	// registerRoutes(v1)
 
 	initrepo := controllers.NewConnection()
+	// The runner is the only generator of scheduler tasks, so it is on
+	// unless explicitly turned off.
+	if helper.GodotEnv("SCHEDULER_RUNNER") != "false" {
+		go initrepo.RunScheduler(time.Minute)
+	}
+	if helper.GodotEnv("SLA_EVALUATOR") == "true" {
+		go initrepo.RunSlaEvaluator(5 * time.Minute)
+	}
+	if helper.GodotEnv("ESCALATION_RUNNER") == "true" {
+		go initrepo.RunEscalation(5 * time.Minute)
+	}
+	// Group task emails only go out through the digest.
+	if helper.GodotEnv("GROUP_DIGEST") != "false" {
+		go initrepo.RunGroupDigest(time.Hour)
+	}
+	// Delegated tasks only go back to their owner through this runner.
+	if helper.GodotEnv("OUT_OF_OFFICE_RUNNER") != "false" {
+		go initrepo.RunOutOfOffice(15 * time.Minute)
+	}
 
 	v1 := r.Group("/api/v1")
 	{
@@ -101,6 +121,75 @@ func setupRouter() *gin.Engine {
 			Tasklist.POST("/InsertUpdategroupAssignTO", initrepo.InsertUpdategroupAssignTO)
 			Tasklist.POST("/CreateCategory", initrepo.CreateCategory)
 			Tasklist.POST("/InsertingSchedulerMasterTask", initrepo.InsertingSchedulerMasterTask)
+			Tasklist.POST("/PreviewRecurrence", initrepo.PreviewRecurrence)
+			Tasklist.POST("/UpdatingSchedulerMasterTask", initrepo.UpdatingSchedulerMasterTask)
+			Tasklist.POST("/PausingSchedulerMasterTask", initrepo.PausingSchedulerMasterTask)
+			Tasklist.POST("/ResumingSchedulerMasterTask", initrepo.ResumingSchedulerMasterTask)
+			Tasklist.POST("/DeletingSchedulerMasterTask", initrepo.DeletingSchedulerMasterTask)
+			Tasklist.POST("/TriggeringSchedulerMasterTask", initrepo.TriggeringSchedulerMasterTask)
+			Tasklist.GET("/GetSchedulerRunLog", initrepo.GetSchedulerRunLog)
+			Tasklist.GET("/GetWorkingPattern", initrepo.GetWorkingPattern)
+			Tasklist.POST("/UpdatingWorkingPattern", initrepo.UpdatingWorkingPattern)
+			Tasklist.GET("/GetHolidays", initrepo.GetHolidays)
+			Tasklist.POST("/InsertingHoliday", initrepo.InsertingHoliday)
+			Tasklist.POST("/DeletingHoliday", initrepo.DeletingHoliday)
+			Tasklist.POST("/ImportHolidayICal", initrepo.ImportHolidayICal)
+			Tasklist.GET("/GetDepartmentClosures", initrepo.GetDepartmentClosures)
+			Tasklist.POST("/InsertingDepartmentClosure", initrepo.InsertingDepartmentClosure)
+			Tasklist.POST("/DeletingDepartmentClosure", initrepo.DeletingDepartmentClosure)
+			Tasklist.GET("/CalculateWorkingDate", initrepo.CalculateWorkingDate)
+			Tasklist.GET("/GetSlaPolicies", initrepo.GetSlaPolicies)
+			Tasklist.POST("/SavingSlaPolicy", initrepo.SavingSlaPolicy)
+			Tasklist.POST("/DeletingSlaPolicy", initrepo.DeletingSlaPolicy)
+			Tasklist.GET("/GetSlaBreachEvents", initrepo.GetSlaBreachEvents)
+			Tasklist.POST("/EvaluatingSla", initrepo.EvaluatingSla)
+			Tasklist.GET("/GetEscalationRules", initrepo.GetEscalationRules)
+			Tasklist.POST("/SavingEscalationRule", initrepo.SavingEscalationRule)
+			Tasklist.POST("/DeletingEscalationRule", initrepo.DeletingEscalationRule)
+			Tasklist.GET("/GetEscalatedToMe", initrepo.GetEscalatedToMe)
+			Tasklist.GET("/GetAssigneeSuggestions", initrepo.GetAssigneeSuggestions)
+			Tasklist.POST("/InsertingEffortLog", initrepo.InsertingEffortLog)
+			Tasklist.GET("/GetEffortLog", initrepo.GetEffortLog)
+			Tasklist.POST("/InsertingOutOfOffice", initrepo.InsertingOutOfOffice)
+			Tasklist.GET("/GetOutOfOffice", initrepo.GetOutOfOffice)
+			Tasklist.POST("/DeletingOutOfOffice", initrepo.DeletingOutOfOffice)
+			Tasklist.GET("/GetGroupDistributionPolicies", initrepo.GetGroupDistributionPolicies)
+			Tasklist.POST("/SavingGroupDistributionPolicy", initrepo.SavingGroupDistributionPolicy)
+			Tasklist.GET("/GetUnclaimedGroupTasks", initrepo.GetUnclaimedGroupTasks)
+			Tasklist.POST("/ClaimingGroupTask", initrepo.ClaimingGroupTask)
+			Tasklist.GET("/GetGroupSubscriptions", initrepo.GetGroupSubscriptions)
+			Tasklist.POST("/UnsubscribingGroupNotification", initrepo.UnsubscribingGroupNotification)
+			Tasklist.POST("/SubscribingGroupNotification", initrepo.SubscribingGroupNotification)
+			Tasklist.POST("/RequestingReassignment", initrepo.RequestingReassignment)
+			Tasklist.GET("/GetReassignmentRequests", initrepo.GetReassignmentRequests)
+			Tasklist.POST("/ApprovingReassignment", initrepo.ApprovingReassignment)
+			Tasklist.POST("/RejectingReassignment", initrepo.RejectingReassignment)
+			Tasklist.GET("/GetTaskAssignees", initrepo.GetTaskAssignees)
+			Tasklist.POST("/AddingTaskContributor", initrepo.AddingTaskContributor)
+			Tasklist.POST("/RemovingTaskContributor", initrepo.RemovingTaskContributor)
+			Tasklist.POST("/SavingTaskCompletionRule", initrepo.SavingTaskCompletionRule)
+			Tasklist.POST("/MarkingAssigneeCompletion", initrepo.MarkingAssigneeCompletion)
+			Tasklist.GET("/GetGroups", initrepo.GetGroups)
+			Tasklist.GET("/GetGroup", initrepo.GetGroup)
+			Tasklist.POST("/CreatingGroup", initrepo.CreatingGroup)
+			Tasklist.POST("/UpdatingGroup", initrepo.UpdatingGroup)
+			Tasklist.POST("/DeletingGroup", initrepo.DeletingGroup)
+			Tasklist.POST("/AddingGroupMember", initrepo.AddingGroupMember)
+			Tasklist.POST("/RemovingGroupMember", initrepo.RemovingGroupMember)
+			Tasklist.GET("/GetGroupMembershipHistory", initrepo.GetGroupMembershipHistory)
+			Tasklist.GET("/GetAssignmentAnalytics", initrepo.GetAssignmentAnalytics)
+			Tasklist.POST("/EditingComment", initrepo.EditingComment)
+			Tasklist.POST("/DeletingComment", initrepo.DeletingComment)
+			Tasklist.GET("/GetCommentRevisions", initrepo.GetCommentRevisions)
+			Tasklist.GET("/GetCommentReactionSet", initrepo.GetCommentReactionSet)
+			Tasklist.POST("/AddingCommentReaction", initrepo.AddingCommentReaction)
+			Tasklist.POST("/RemovingCommentReaction", initrepo.RemovingCommentReaction)
+			Tasklist.POST("/InsertingCommentMultipart", initrepo.InsertingCommentMultipart)
+			Tasklist.GET("/DownloadingFile", initrepo.DownloadingFile)
+			Tasklist.HEAD("/DownloadingFile", initrepo.DownloadingFile)
+			Tasklist.POST("/PresigningDocumentUpload", initrepo.PresigningDocumentUpload)
+			Tasklist.POST("/CompletingDocumentUpload", initrepo.CompletingDocumentUpload)
+			Tasklist.GET("/PresigningDocumentDownload", initrepo.PresigningDocumentDownload)
 			Tasklist.POST("/InsertingSubtask", initrepo.InsertingSubtask)
 			Tasklist.POST("/SendingNotifDone", initrepo.SendingNotifDone)
 			Tasklist.GET("/GetTaskID", initrepo.GetTaskID)
@@ -108,6 +197,7 @@ func setupRouter() *gin.Engine {
 
 		}
 	}
+	r.GET("/health", initrepo.Health)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.Run(":8086")
 	return r
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type ParamAssignmentAnalytics struct {
+	Departemen string `json:"departemen" form:"departemen"`
+	Start_Date string `json:"start_date" form:"start_date"`
+	End_Date   string `json:"end_date" form:"end_date"`
+	Top        int    `json:"top" form:"top"`
+}
+
+// AssignHistoryRow is one User_Assign_History span of any task created in
+// a date range.
+type AssignHistoryRow struct {
+	Task_ID        string     `json:"task_id"`
+	Departemen     string     `json:"departemen"`
+	Group_Assign   string     `json:"group_assign"`
+	User_Assign_To string     `json:"user_assign_to"`
+	Emp_Name       string     `json:"emp_name"`
+	Start_Date     time.Time  `json:"start_date"`
+	End_Date       *time.Time `json:"end_date"`
+}
+
+// QueryGetAssignHistoryRange runs User_Assign_History for every task
+// created in a date range. It takes the department twice (empty for all)
+// and then the range. Group_Assign is the group the task is assigned to now.
+var QueryGetAssignHistoryRange = `Select d."task_id", d."departemen", d."assign_to" AS "group_assign", t."user_assign_to", t."emp_name", t."start_date", t."end_date"
+	from public."task_detail" d
+	cross join lateral "public"."User_Assign_History"(d."task_id") AS ` + tablereturnuserassignhistory + `
+	where (? = '' or d."departemen" = ?) and d."created_at" >= ? and d."created_at" < ?`
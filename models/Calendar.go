@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// CalendarWorkingPattern is the working window of one weekday
+// (0 = Sunday ... 6 = Saturday).
+type CalendarWorkingPattern struct {
+	Weekday   int       `json:"weekday" gorm:"primaryKey;autoIncrement:false;"`
+	IsWorking bool      `json:"is_working" gorm:"not null;"`
+	StartTime string    `json:"start_time" gorm:"type:varchar(5);"`
+	EndTime   string    `json:"end_time" gorm:"type:varchar(5);"`
+	UpdatedBy string    `json:"updated_by" gorm:"type:varchar(30);"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (CalendarWorkingPattern) TableName() string {
+	return "calendar_working_pattern"
+}
+
+type CalendarHoliday struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Date      time.Time `json:"date" gorm:"type:date;uniqueIndex;"`
+	Name      string    `json:"name" gorm:"type:varchar(200);"`
+	Source    string    `json:"source" gorm:"type:varchar(20);"`
+	CreatedBy string    `json:"created_by" gorm:"type:varchar(30);"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (CalendarHoliday) TableName() string {
+	return "calendar_holiday"
+}
+
+type CalendarDepartmentClosure struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Departemen string    `json:"departemen" gorm:"type:varchar(100);index;"`
+	StartDate  time.Time `json:"start_date" gorm:"type:date;"`
+	EndDate    time.Time `json:"end_date" gorm:"type:date;"`
+	Reason     string    `json:"reason" gorm:"type:varchar(200);"`
+	CreatedBy  string    `json:"created_by" gorm:"type:varchar(30);"`
+	CreatedAt  time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (CalendarDepartmentClosure) TableName() string {
+	return "calendar_department_closure"
+}
+
+const (
+	HolidaySourceManual = "MANUAL"
+	HolidaySourceICal   = "ICAL"
+)
+
+type ParamWorkingPattern struct {
+	Userid  string                   `json:"userid"`
+	Pattern []CalendarWorkingPattern `json:"pattern" binding:"required"`
+}
+
+type ParamInsertHoliday struct {
+	Date   string `json:"date" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+	Userid string `json:"userid"`
+}
+
+type ParamGetHolidays struct {
+	Year int `json:"year" form:"year"`
+}
+
+type ParamInsertClosure struct {
+	Departemen string `json:"departemen" binding:"required"`
+	Start_Date string `json:"start_date" binding:"required"`
+	End_Date   string `json:"end_date" binding:"required"`
+	Reason     string `json:"reason"`
+	Userid     string `json:"userid"`
+}
+
+type ParamGetClosures struct {
+	Departemen string `json:"departemen" form:"departemen"`
+}
+
+type ParamCalendarID struct {
+	ID int64 `json:"id" binding:"required"`
+}
+
+type ParamCalculateWorkingDate struct {
+	Departemen string `json:"departemen" form:"departemen"`
+	Date       string `json:"date" form:"date" binding:"required"`
+	Days       int    `json:"days" form:"days"`
+}
+
+type ValueCalculateWorkingDate struct {
+	Date             string `json:"date"`
+	Days             int    `json:"days"`
+	Result_Date      string `json:"result_date"`
+	Is_Working_Day   bool   `json:"is_working_day"`
+	Non_Working_Note string `json:"non_working_note"`
+}
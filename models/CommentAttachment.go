@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TaskCommentAttachment is one file attached to a comment. Comments posted
+// before attachments had their own table carry a single File_ID instead.
+type TaskCommentAttachment struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	CommentID  string    `json:"comment_id" gorm:"type:varchar(100);index;"`
+	TaskID     string    `json:"task_id" gorm:"type:varchar(100);index;"`
+	FileID     string    `json:"file_id" gorm:"type:varchar(100);"`
+	FileName   string    `json:"file_name" gorm:"type:varchar(255);"`
+	Size       int64     `json:"size"`
+	MimeType   string    `json:"mime_type" gorm:"type:varchar(100);"`
+	UploadedBy string    `json:"uploaded_by" gorm:"type:varchar(30);"`
+	CreatedAt  time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (TaskCommentAttachment) TableName() string {
+	return "task_comment_attachment"
+}
@@ -0,0 +1,23 @@
+package models
+
+// TaskCommentMention is one "@" mention in a comment, resolved against the
+// user directory. Start and Length count runes and include the "@".
+type TaskCommentMention struct {
+	CommentID string `json:"comment_id" gorm:"primaryKey;type:varchar(100);"`
+	Start     int    `json:"start" gorm:"primaryKey;autoIncrement:false;"`
+	Length    int    `json:"length"`
+	TaskID    string `json:"task_id" gorm:"type:varchar(100);index;"`
+	EmpNo     string `json:"emp_no" gorm:"type:varchar(30);"`
+	EmpName   string `json:"emp_name" gorm:"type:varchar(100);"`
+	Text      string `json:"text" gorm:"type:varchar(255);"`
+}
+
+func (TaskCommentMention) TableName() string {
+	return "task_comment_mention"
+}
+
+var QueryFindUsersByNumber = `Select number_officer,name from users where number_officer in ?`
+
+// QueryFindUsers is completed with one "name like ?" condition per name
+// prefix, joined with "or".
+var QueryFindUsers = `Select number_officer,name from users where `
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ValueCommentPage is one page of GetListtComments. Comments holds
+// GetCommentList or, when threaded, ValueCommentThread entries.
+type ValueCommentPage struct {
+	Comments    interface{} `json:"comments"`
+	Next_Cursor string      `json:"next_cursor"`
+	Sync_Cursor string      `json:"sync_cursor"`
+}
+
+// ValueCommentChanges lists what happened to the comments of a task after
+// a sync cursor.
+type ValueCommentChanges struct {
+	Added       []GetCommentList `json:"added"`
+	Edited      []GetCommentList `json:"edited"`
+	Deleted     []string         `json:"deleted"`
+	Sync_Cursor string           `json:"sync_cursor"`
+}
+
+// QueryGetVisibleComments selects the comments of a task that were not
+// deleted. Conditions are appended with "and"; s is the state of the
+// comment, p its thread link and ps the state of its parent.
+var QueryGetVisibleComments = `select t.* from public.Get_List_Comments(?) ` + TableReturnedComments + `
+	left join public."task_comment_state" s on s."comment_id" = t."Comment_ID"
+	left join public."task_comment_thread" p on p."comment_id" = t."Comment_ID"
+	left join public."task_comment_state" ps on ps."comment_id" = p."parent_id"
+	where s."deleted_at" is null`
+
+// QueryGetCommentSyncPoint reads the latest post, edit and deletion among
+// the comments of a task.
+var QueryGetCommentSyncPoint = `select
+	(select max(t."Comment_Date")::text from public.Get_List_Comments(?) ` + TableReturnedComments + `
+		left join public."task_comment_state" s on s."comment_id" = t."Comment_ID"
+		where s."deleted_at" is null) as "posted",
+	(select max("edited_at") from public."task_comment_state" where "task_id" = ?) as "edited",
+	(select max("deleted_at") from public."task_comment_state" where "task_id" = ?) as "deleted"`
+
+// ValueCommentSyncPoint is the row of QueryGetCommentSyncPoint.
+type ValueCommentSyncPoint struct {
+	Posted  *string
+	Edited  *time.Time
+	Deleted *time.Time
+}
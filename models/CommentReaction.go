@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// CommentReactions is the fixed set of reactions, in the order they are
+// listed, with the emoji each one shows.
+var CommentReactions = []struct {
+	Code  string
+	Emoji string
+}{
+	{"THUMBS_UP", "👍"},
+	{"HEART", "❤️"},
+	{"LAUGH", "😄"},
+	{"CELEBRATE", "🎉"},
+	{"EYES", "👀"},
+	{"DONE", "✅"},
+}
+
+// TaskCommentReaction is one person's reaction to a comment.
+type TaskCommentReaction struct {
+	CommentID string    `json:"comment_id" gorm:"primaryKey;type:varchar(100);"`
+	EmpNo     string    `json:"emp_no" gorm:"primaryKey;type:varchar(30);"`
+	Reaction  string    `json:"reaction" gorm:"primaryKey;type:varchar(20);"`
+	TaskID    string    `json:"task_id" gorm:"type:varchar(100);index;"`
+	EmpName   string    `json:"emp_name" gorm:"type:varchar(100);"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (TaskCommentReaction) TableName() string {
+	return "task_comment_reaction"
+}
+
+type ParamCommentReaction struct {
+	Comment_ID string `json:"comment_id" binding:"required"`
+	Reaction   string `json:"reaction" binding:"required"`
+	Userid     string `json:"userid" binding:"required"`
+}
+
+type ValueReactedBy struct {
+	Emp_No   string `json:"emp_no"`
+	Emp_Name string `json:"emp_name"`
+}
+
+// ValueCommentReaction aggregates one reaction on one comment.
+type ValueCommentReaction struct {
+	Reaction   string           `json:"reaction"`
+	Emoji      string           `json:"emoji"`
+	Count      int              `json:"count"`
+	Reacted_By []ValueReactedBy `json:"reacted_by"`
+}
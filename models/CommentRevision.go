@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// TaskCommentState overrides a row of task_comments once it has been edited
+// or deleted. Comments that were never touched have no state.
+type TaskCommentState struct {
+	CommentID   string     `json:"comment_id" gorm:"primaryKey;type:varchar(100);"`
+	TaskID      string     `json:"task_id" gorm:"type:varchar(100);index;"`
+	Comments    string     `json:"comments" gorm:"type:text;"`
+	ContentName string     `json:"content_name" gorm:"type:varchar(255);"`
+	FileID      string     `json:"file_id" gorm:"type:varchar(100);"`
+	Revision    int        `json:"revision"`
+	EditedBy    string     `json:"edited_by" gorm:"type:varchar(30);"`
+	EditedAt    *time.Time `json:"edited_at" gorm:"type:timestamp;"`
+	DeletedBy   string     `json:"deleted_by" gorm:"type:varchar(30);"`
+	DeletedAt   *time.Time `json:"deleted_at" gorm:"type:timestamp;"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"type:timestamp;index;"`
+}
+
+func (TaskCommentState) TableName() string {
+	return "task_comment_state"
+}
+
+// TaskCommentRevision is a version of a comment that was replaced by an
+// edit. Revision 0 is the text as first posted.
+type TaskCommentRevision struct {
+	ID           int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	CommentID    string    `json:"comment_id" gorm:"type:varchar(100);index;"`
+	Revision     int       `json:"revision"`
+	Comments     string    `json:"comments" gorm:"type:text;"`
+	CommentsHtml string    `json:"comments_html" gorm:"-"`
+	ContentName  string    `json:"content_name" gorm:"type:varchar(255);"`
+	FileID       string    `json:"file_id" gorm:"type:varchar(100);"`
+	WrittenBy    string    `json:"written_by" gorm:"type:varchar(30);"`
+	WrittenAt    time.Time `json:"written_at" gorm:"type:timestamp;"`
+}
+
+func (TaskCommentRevision) TableName() string {
+	return "task_comment_revision"
+}
+
+type ParamEditingComment struct {
+	Comment_ID        string `json:"comment_id" binding:"required"`
+	Comments          string `json:"comments" binding:"required"`
+	Remove_Attachment bool   `json:"remove_attachment"`
+	Userid            string `json:"userid" binding:"required"`
+}
+
+type ParamDeletingComment struct {
+	Comment_ID string `json:"comment_id" binding:"required"`
+	Userid     string `json:"userid" binding:"required"`
+}
+
+type ParamCommentID struct {
+	Comment_ID string `json:"comment_id" form:"comment_id" binding:"required"`
+}
+
+var QueryGetListCommentsByTask = "Select * from public.Get_List_Comments(?)" + TableReturnedComments
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TaskCommentThread links a reply to the comment that started its thread.
+// Threads are one level deep: a reply to a reply joins the same thread.
+type TaskCommentThread struct {
+	CommentID string    `json:"comment_id" gorm:"primaryKey;type:varchar(100);"`
+	TaskID    string    `json:"task_id" gorm:"type:varchar(100);index;"`
+	ParentID  string    `json:"parent_id" gorm:"type:varchar(100);index;"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (TaskCommentThread) TableName() string {
+	return "task_comment_thread"
+}
+
+// ValueCommentThread is a top-level comment with its replies, oldest first.
+type ValueCommentThread struct {
+	GetCommentList
+	Replies []GetCommentList `json:"Replies"`
+}
+
+// ValueReplyCount is the number of replies to a comment that were not
+// deleted.
+type ValueReplyCount struct {
+	Parent_ID string `json:"parent_id"`
+	Replies   int    `json:"replies"`
+}
+
+var QueryCountReplies = `select l."parent_id", count(*) as "replies" from public."task_comment_thread" l
+	left join public."task_comment_state" s on s."comment_id" = l."comment_id"
+	where l."task_id" = ? and l."parent_id" in ? and s."deleted_at" is null
+	group by l."parent_id"`
+
+// QueryInsertingCommentReturningID posts a comment through
+// SP_InsertingComments and returns the new Comment_ID.
+var QueryInsertingCommentReturningID = `select public."inserting_comment_returning_id"(?, ?, ?, ?, ?, ?)`
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type InsertComments struct {
 	Task_ID      string   `json:"Task_ID" binding:"required"`
 	Comments     string   `json:"Comments" binding:"required"`
@@ -7,16 +9,26 @@ type InsertComments struct {
 	Content_Name string   `json:"Content_Name"`
 	File_Path    string   `json:"File_Path"`
 	Tagging_User []string `json:"Tagging_User"`
+	Parent_ID    string   `json:"Parent_ID"`
 }
 
 type GetCommentList struct {
-	Comment_ID   string `json:"Comment_ID"  gorm:"type:varchar(100);"`
-	Emp_ID       string `json:"Emp_ID"  gorm:"type:varchar(100);"`
-	Emp_NAME     string `json:"Emp_NAME"  gorm:"type:varchar(100);"`
-	Comment_Date string `json:"Comment_Date"  gorm:"type:timestamp;"`
-	Comments     string `json:"Comments"  gorm:"type:varchar(100);"`
-	Content_Name string `json:"Content_Name"  gorm:"type:varchar(100);"`
-	File_ID      string `json:"File_ID"  gorm:"type:varchar(100);"`
+	Comment_ID    string                  `json:"Comment_ID"  gorm:"type:varchar(100);"`
+	Emp_ID        string                  `json:"Emp_ID"  gorm:"type:varchar(100);"`
+	Emp_NAME      string                  `json:"Emp_NAME"  gorm:"type:varchar(100);"`
+	Comment_Date  string                  `json:"Comment_Date"  gorm:"type:timestamp;"`
+	Comments      string                  `json:"Comments"  gorm:"type:varchar(100);"`
+	Comments_Html string                  `json:"Comments_Html" gorm:"-"`
+	Content_Name  string                  `json:"Content_Name"  gorm:"type:varchar(100);"`
+	File_ID       string                  `json:"File_ID"  gorm:"type:varchar(100);"`
+	Edited        bool                    `json:"Edited" gorm:"-"`
+	Edited_At     *time.Time              `json:"Edited_At" gorm:"-"`
+	Revision      int                     `json:"Revision" gorm:"-"`
+	Parent_ID     string                  `json:"Parent_ID" gorm:"-"`
+	Reply_Count   int                     `json:"Reply_Count" gorm:"-"`
+	Mentions      []TaskCommentMention    `json:"Mentions" gorm:"-"`
+	Reactions     []ValueCommentReaction  `json:"Reactions" gorm:"-"`
+	Attachments   []TaskCommentAttachment `json:"Attachments" gorm:"-"`
 }
 type Configuration struct {
 	RemoveUnused     bool   // Whether to remove unused objects
@@ -26,7 +38,12 @@ type Configuration struct {
 	Password         string // Password for encryption if enabled
 }
 type ParamComments struct {
-	Task_ID string `json:"task_id" gorm:"text;"`
+	Task_ID  string `json:"task_id" gorm:"text;"`
+	Threaded bool   `json:"threaded" form:"threaded"`
+	Limit    int    `json:"limit" form:"limit"`
+	Order    string `json:"order" form:"order"`
+	Cursor   string `json:"cursor" form:"cursor"`
+	Since    string `json:"since" form:"since"`
 }
 type ParamGetAttchment struct {
 	ObjectID string `json:"objectid" gorm:"text;"`
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// TaskDocumentPresign is an upload URL handed out for a document of a task.
+// The document is recorded with insert_task_document_upload once the client
+// reports the upload as done.
+type TaskDocumentPresign struct {
+	ObjectKey    string     `json:"ObjectKey" gorm:"type:varchar(100);primaryKey;"`
+	TaskID       string     `json:"TaskID" gorm:"type:varchar(100);index;"`
+	DocumentType string     `json:"DocumentType" gorm:"type:varchar(100);"`
+	DocumentName string     `json:"DocumentName" gorm:"type:varchar(255);"`
+	ContentType  string     `json:"ContentType" gorm:"type:varchar(100);"`
+	Size         int64      `json:"Size"`
+	ExpiresAt    time.Time  `json:"ExpiresAt" gorm:"type:timestamp;"`
+	CompletedAt  *time.Time `json:"CompletedAt" gorm:"type:timestamp;"`
+	CreatedAt    time.Time  `json:"CreatedAt" gorm:"type:timestamp;"`
+}
+
+func (TaskDocumentPresign) TableName() string {
+	return "task_document_presign"
+}
+
+type ParamPresigningUpload struct {
+	TaskID       string `json:"TaskID" binding:"required"`
+	DocumentType string `json:"DocumentType" binding:"required"`
+	DocumentName string `json:"DocumentName" binding:"required"`
+	ContentType  string `json:"ContentType"`
+}
+
+type ParamCompletingUpload struct {
+	ObjectKey string `json:"ObjectKey" binding:"required"`
+	Status    string `json:"Status" binding:"required"`
+}
+
+type ParamPresigningDownload struct {
+	TaskID       string `json:"TaskID" form:"TaskID" binding:"required"`
+	DocumentType string `json:"DocumentType" form:"DocumentType" binding:"required"`
+	ObjectKey    string `json:"ObjectKey" form:"ObjectKey" binding:"required"`
+}
+
+type ValuePresignedURL struct {
+	ObjectKey string            `json:"ObjectKey"`
+	Method    string            `json:"Method"`
+	Url       string            `json:"Url"`
+	Headers   map[string]string `json:"Headers"`
+	ExpiresAt time.Time         `json:"ExpiresAt"`
+}
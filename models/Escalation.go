@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// EscalationRule is one step of the escalation ladder: once a matching task
+// is AfterHours working hours overdue it is escalated Level steps up the
+// supervisor chain (1 = direct supervisor). Empty Priority, Category or
+// Departemen match any value. Active is a pointer so that false is saved
+// rather than replaced by the column default.
+type EscalationRule struct {
+	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Priority        string    `json:"priority" gorm:"type:varchar(100);"`
+	Category        string    `json:"category" gorm:"type:varchar(100);"`
+	Departemen      string    `json:"departemen" gorm:"type:varchar(100);"`
+	Level           int       `json:"level" gorm:"not null;" binding:"required"`
+	AfterHours      int       `json:"after_hours" gorm:"not null;"`
+	RaisePriorityTo string    `json:"raise_priority_to" gorm:"type:varchar(100);"`
+	Active          *bool     `json:"active" gorm:"not null;default:true;"`
+	UpdatedBy       string    `json:"updated_by" gorm:"type:varchar(30);"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (EscalationRule) TableName() string {
+	return "escalation_rule"
+}
+
+// EscalationEvent is recorded once per task and level.
+type EscalationEvent struct {
+	ID              int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	TaskID          string    `json:"task_id" gorm:"type:varchar(100);uniqueIndex:idx_escalation_event;"`
+	Level           int       `json:"level" gorm:"uniqueIndex:idx_escalation_event;"`
+	RuleID          int64     `json:"rule_id"`
+	Subject         string    `json:"subject" gorm:"type:varchar(255);"`
+	AssignTo        string    `json:"assign_to" gorm:"type:varchar(30);"`
+	EscalatedTo     string    `json:"escalated_to" gorm:"type:varchar(30);index;"`
+	EscalatedToName string    `json:"escalated_to_name" gorm:"type:varchar(100);"`
+	OverdueHours    float64   `json:"overdue_hours"`
+	RaisedPriority  string    `json:"raised_priority" gorm:"type:varchar(100);"`
+	CreatedAt       time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (EscalationEvent) TableName() string {
+	return "escalation_event"
+}
+
+// OverdueTask is an unfinished task past its estimated time done.
+type OverdueTask struct {
+	Task_ID             string    `json:"task_id"`
+	Departemen          string    `json:"departemen"`
+	Priority            string    `json:"priority"`
+	Topic               string    `json:"topic"`
+	Subject             string    `json:"subject"`
+	User_Assign_To      string    `json:"user_assign_to"`
+	Estimated_Time_Done time.Time `json:"estimated_time_done"`
+}
+
+var QueryGetOverdueTask = `Select * from public.get_overdue_task() AS t("task_id" varchar,"departemen" varchar,"priority" varchar,"topic" varchar,"subject" varchar,"user_assign_to" varchar,"estimated_time_done" timestamp)`
+
+type ParamEscalatedToMe struct {
+	Userid string `json:"userid" form:"userid" binding:"required"`
+}
+
+type ParamEscalationRuleID struct {
+	ID int64 `json:"id" binding:"required"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+const (
+	GroupMembershipAdded   = "ADDED"
+	GroupMembershipRemoved = "REMOVED"
+	GroupLeaderChanged     = "LEADER"
+)
+
+// GroupProfile holds what dynamic_group does not: a description and the
+// group leader. Memberships themselves stay in dynamic_group.
+type GroupProfile struct {
+	GroupName   string    `json:"group_name" gorm:"primaryKey;type:varchar(100);"`
+	Description string    `json:"description" gorm:"type:varchar(255);"`
+	LeaderEmpNo string    `json:"leader_emp_no" gorm:"type:varchar(30);"`
+	CreatedBy   string    `json:"created_by" gorm:"type:varchar(30);"`
+	CreatedAt   time.Time `json:"created_at" gorm:"type:timestamp;"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (GroupProfile) TableName() string {
+	return "group_profile"
+}
+
+// GroupMembershipHistory is one change to the members or leader of a group.
+type GroupMembershipHistory struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	GroupName string    `json:"group_name" gorm:"type:varchar(100);index;"`
+	EmpNo     string    `json:"emp_no" gorm:"type:varchar(30);"`
+	EmpName   string    `json:"emp_name" gorm:"type:varchar(100);"`
+	Action    string    `json:"action" gorm:"type:varchar(20);"`
+	ChangedBy string    `json:"changed_by" gorm:"type:varchar(30);"`
+	ChangedAt time.Time `json:"changed_at" gorm:"type:timestamp;"`
+}
+
+func (GroupMembershipHistory) TableName() string {
+	return "group_membership_history"
+}
+
+type ValueGroup struct {
+	Group_Name    string             `json:"group_name"`
+	Description   string             `json:"description"`
+	Leader_Emp_No string             `json:"leader_emp_no"`
+	Members       []ListDataAssignTo `json:"members"`
+}
+
+type ParamCreatingGroup struct {
+	Group_Name  string   `json:"group_name" binding:"required"`
+	Description string   `json:"description"`
+	Leader      string   `json:"leader"`
+	Members     []string `json:"members"`
+	Userid      string   `json:"userid" binding:"required"`
+}
+
+type ParamUpdatingGroup struct {
+	Group_Name  string `json:"group_name" binding:"required"`
+	Description string `json:"description"`
+	Leader      string `json:"leader"`
+	Userid      string `json:"userid" binding:"required"`
+}
+
+type ParamGroupName struct {
+	Group_Name string `json:"group_name" form:"group_name" binding:"required"`
+	Userid     string `json:"userid" form:"userid"`
+}
+
+type ParamGroupMember struct {
+	Group_Name string `json:"group_name" binding:"required"`
+	Emp_No     string `json:"emp_no" binding:"required"`
+	Userid     string `json:"userid" binding:"required"`
+}
+
+var QueryGetGroupNames = `select distinct "group_name" from public."dynamic_group" where "group_name" like '%GROUP%' order by "group_name"`
+var QueryInsertGroupMember = `insert into public."dynamic_group" ("emp_no","emp_name","group_name") values (?, ?, ?)`
+var QueryDeleteGroupMember = `delete from public."dynamic_group" where "emp_no" = ? and "group_name" = ?`
+
+// QueryCountOpenGroupTasks counts the unfinished tasks assigned to a group,
+// whether a member holds them or they wait to be claimed.
+var QueryCountOpenGroupTasks = `select count(*) from public."task_detail" where "assign_to" = ? and "task_progress" not in ('DONE', 'CLOSE')`
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	GroupStrategyRoundRobin  = "ROUND_ROBIN"
+	GroupStrategyLeastLoaded = "LEAST_LOADED"
+	GroupStrategyManualClaim = "MANUAL_CLAIM"
+)
+
+// AssignParamUpdate is the p_param the task screen passes to
+// user_assign_group_procedure to move a task to a member. Moves the API
+// makes on its own pass it too; their reason is kept in their own tables.
+const AssignParamUpdate = "UPDATE"
+
+// GroupDistributionPolicy is how tasks assigned to a group are handed to its
+// members. Groups without a policy use manual claim.
+type GroupDistributionPolicy struct {
+	GroupName      string    `json:"group_name" gorm:"primaryKey;type:varchar(100);" binding:"required"`
+	Strategy       string    `json:"strategy" gorm:"type:varchar(20);not null;" binding:"required"`
+	LastAssignedTo string    `json:"last_assigned_to" gorm:"type:varchar(30);"`
+	UpdatedBy      string    `json:"updated_by" gorm:"type:varchar(30);"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (GroupDistributionPolicy) TableName() string {
+	return "group_distribution_policy"
+}
+
+// GroupTaskAssignment records which member a group task went to. EmpNo is
+// empty while the task waits to be claimed.
+type GroupTaskAssignment struct {
+	TaskID     string     `json:"task_id" gorm:"primaryKey;type:varchar(100);"`
+	GroupName  string     `json:"group_name" gorm:"type:varchar(100);index;"`
+	Strategy   string     `json:"strategy" gorm:"type:varchar(20);"`
+	EmpNo      string     `json:"emp_no" gorm:"type:varchar(30);index;"`
+	AssignedBy string     `json:"assigned_by" gorm:"type:varchar(30);"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"type:timestamp;"`
+	AssignedAt *time.Time `json:"assigned_at" gorm:"type:timestamp;"`
+}
+
+func (GroupTaskAssignment) TableName() string {
+	return "group_task_assignment"
+}
+
+type ParamGroupFilter struct {
+	Group_Name string `json:"group_name" form:"group_name"`
+}
+
+type ParamClaimGroupTask struct {
+	Task_ID string `json:"task_id" binding:"required"`
+	Userid  string `json:"userid" binding:"required"`
+}
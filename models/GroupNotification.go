@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// GroupNotificationOptOut marks a member who no longer wants notifications
+// for tasks assigned to one of their groups.
+type GroupNotificationOptOut struct {
+	EmpNo     string    `json:"emp_no" gorm:"primaryKey;type:varchar(30);" binding:"required"`
+	GroupName string    `json:"group_name" gorm:"primaryKey;type:varchar(100);" binding:"required"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (GroupNotificationOptOut) TableName() string {
+	return "group_notification_opt_out"
+}
+
+// GroupDigestEntry is one group task waiting to go out in a member's next
+// digest email.
+type GroupDigestEntry struct {
+	ID        int64      `json:"id" gorm:"primaryKey;autoIncrement;"`
+	EmpNo     string     `json:"emp_no" gorm:"type:varchar(30);index;"`
+	GroupName string     `json:"group_name" gorm:"type:varchar(100);"`
+	TaskID    string     `json:"task_id" gorm:"type:varchar(100);"`
+	Subject   string     `json:"subject" gorm:"type:varchar(255);"`
+	Reporter  string     `json:"reporter" gorm:"type:varchar(30);"`
+	CreatedAt time.Time  `json:"created_at" gorm:"type:timestamp;"`
+	SentAt    *time.Time `json:"sent_at" gorm:"type:timestamp;index;"`
+}
+
+func (GroupDigestEntry) TableName() string {
+	return "group_digest_entry"
+}
+
+type ParamGroupSubscription struct {
+	Userid string `json:"userid" form:"userid" binding:"required"`
+}
+
+type ValueGroupSubscription struct {
+	Group_Name string `json:"group_name"`
+	Subscribed bool   `json:"subscribed"`
+}
+
+type ParamGroupOptOut struct {
+	Userid     string `json:"userid" binding:"required"`
+	Group_Name string `json:"group_name" binding:"required"`
+}
+
+// QueryGetGroupsOfMember lists the groups an employee belongs to.
+var QueryGetGroupsOfMember = `select distinct "group_name" from public."dynamic_group" where "emp_no" = ? order by "group_name"`
@@ -0,0 +1,27 @@
+package models
+
+// ValueDatabaseHealth is the result of pinging one database.
+type ValueDatabaseHealth struct {
+	Up         bool   `json:"up"`
+	Latency_Ms int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ValueMongoHealth adds the connection pool of the shared MongoDB client.
+type ValueMongoHealth struct {
+	ValueDatabaseHealth
+	Open_Connections     int64 `json:"open_connections"`
+	In_Use_Connections   int64 `json:"in_use_connections"`
+	Created_Connections  int64 `json:"created_connections_total"`
+	Closed_Connections   int64 `json:"closed_connections_total"`
+	Checkout_Failures    int64 `json:"checkout_failures_total"`
+	Sessions_In_Progress int   `json:"sessions_in_progress"`
+}
+
+type ValueHealth struct {
+	Status     string              `json:"status"`
+	Postgres   ValueDatabaseHealth `json:"postgres"`
+	MySQL      ValueDatabaseHealth `json:"mysql"`
+	Mongo      ValueMongoHealth    `json:"mongo"`
+	Blob_Store string              `json:"blob_store"`
+}
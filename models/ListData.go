@@ -19,6 +19,8 @@ type ListDataHeader struct {
 	Reporter            string `json:"reporter"  gorm:"type:varchar(100)"`
 	Color               string `json:"color" gorm:"type:varchar(100)"`
 	Task_id_parent_of   string `json:"task_id_parent_of" gorm:"type:varchar(100)"`
+	Assignee_Role       string `json:"assignee_role" gorm:"-"`
+	TaskSlaFields
 }
 type Getdetailtoreassign struct {
 	Subject             string `json:"subject"  gorm:"type:varchar(100);"`
@@ -34,6 +36,7 @@ type ListDataDetail struct {
 	Topic               string `json:"topic" gorm:"type:varchar(100);"`
 	Subject             string `json:"subject" gorm:"type:varchar(100);"`
 	Task_Desc           string `json:"task_desc" gorm:"type:varchar(9999);"`
+	Task_Desc_Html      string `json:"task_desc_html" gorm:"-"`
 	Task_Progress       string `json:"task_progress" gorm:"type:varchar(100);"`
 	Assign_To           string `json:"assign_to" gorm:"type:varchar(100);"`
 	User_Assign_To      string `json:"user_assign_to" gorm:"type:varchar(100);"`
@@ -44,6 +47,7 @@ type ListDataDetail struct {
 	Finish_Date         string `json:"finish_date" gorm:"type:timestamp;"`
 	Close_Date          string `json:"close_date" gorm:"type:timestamp;"`
 	Reporter            string `json:"reporter" gorm:"type:varchar(100);"`
+	TaskSlaFields
 }
 type ListIncomingTask struct {
 	TaskCode          string `json:"task_code" gorm:"type:varchar(100);"`
@@ -59,6 +63,8 @@ type ListIncomingTask struct {
 	GenerateEveryDay  int    `json:"generate_every_day" gorm:"type:int;"`
 	RunningAt         string `json:"running_at" gorm:"type:timestamp;"`
 	NextRunningAt     string `json:"next_running_at" gorm:"type:timestamp;"`
+	GenerateEveryText string `json:"generate_every_text" gorm:"-"`
+	Paused            bool   `json:"paused" gorm:"-"`
 }
 
 type ListDataSummary struct {
@@ -186,6 +192,7 @@ var QueryUpdateTask = ""
 type ValueUpdateingTask struct {
 	Task_ID      string `json:"task_id" gorm:"varchar(30);"`
 	ProgresValue string `json:"progresvalue" gorm:"varchar(30);"`
+	Userid       string `json:"userid" gorm:"-"`
 }
 type ValueGetTaskID struct {
 	Task_ID string `json:"task_id" gorm:"varchar(30);"`
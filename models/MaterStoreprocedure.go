@@ -15,6 +15,11 @@ const (
 	Query_GetTaskCategory               = `SELECT name FROM public."task_category"`
 	Query_InsertingDocumentUpload       = `SELECT * from public."insert_task_document_upload"`
 	Query_Tagging                       = `SELECT * FROM Sp_tagging`
+	Query_UpdateSchedulerMasterTaskList = `Call public."Sp_UpdatingSchedulerTask"`
+	Query_DeleteSchedulerMasterTaskList = `Call public."Sp_DeletingSchedulerTask"`
+	Query_GenerateSchedulerTask         = `SELECT * FROM public."generate_scheduler_task"`
+	Query_UpdateTaskPriority            = `Call public."SP_Update_TaskPriority"`
+	Query_UpdateTaskProgress            = `Call public."SP_Update_TaskProgress"`
 )
 
 //("topic_code" text, "subject" text, "dept" text, "task_code" text, "task_name" text, "task_category" text, "generate_every" text, "priority" text, "estimasted_time_done" text, "assign_to" text, "created_date" text)
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	ReassignmentPending  = "PENDING"
+	ReassignmentApproved = "APPROVED"
+	ReassignmentRejected = "REJECTED"
+)
+
+// ReassignmentRequest asks the direct supervisor of a task's assignee to
+// approve moving the task to someone else.
+type ReassignmentRequest struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement;"`
+	TaskID       string     `json:"task_id" gorm:"type:varchar(100);index;"`
+	FromEmpNo    string     `json:"from_emp_no" gorm:"type:varchar(30);"`
+	ToEmpNo      string     `json:"to_emp_no" gorm:"type:varchar(30);"`
+	GroupAssign  string     `json:"group_assign" gorm:"type:varchar(100);"`
+	RequestedBy  string     `json:"requested_by" gorm:"type:varchar(30);index;"`
+	Reason       string     `json:"reason" gorm:"type:text;"`
+	Approver     string     `json:"approver" gorm:"type:varchar(30);index;"`
+	Status       string     `json:"status" gorm:"type:varchar(20);index;"`
+	DecisionNote string     `json:"decision_note" gorm:"type:text;"`
+	DecidedAt    *time.Time `json:"decided_at" gorm:"type:timestamp;"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (ReassignmentRequest) TableName() string {
+	return "reassignment_request"
+}
+
+type ParamRequestReassignment struct {
+	Task_ID      string `json:"task_id" binding:"required"`
+	New_Assign   string `json:"new_assign_to" binding:"required"`
+	Group_Assign string `json:"group_assign"`
+	Requested_By string `json:"requested_by" binding:"required"`
+	Reason       string `json:"reason" binding:"required"`
+}
+
+type ParamReassignmentList struct {
+	Userid string `json:"userid" form:"userid" binding:"required"`
+	Status string `json:"status" form:"status"`
+}
+
+type ParamReassignmentDecision struct {
+	ID     int64  `json:"id" binding:"required"`
+	Userid string `json:"userid" binding:"required"`
+	Note   string `json:"note"`
+}
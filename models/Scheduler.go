@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+type ParamPreviewRecurrence struct {
+	Rule  string `json:"rule" binding:"required"`
+	Start string `json:"start"`
+	Count int    `json:"count"`
+	// Occurrences on non-working days of the department are skipped.
+	Departemen            string `json:"departemen"`
+	Skip_Non_Working_Days bool   `json:"skip_non_working_days"`
+}
+
+type ValuePreviewRecurrence struct {
+	Rule        string   `json:"rule"`
+	Valid       bool     `json:"valid"`
+	Description string   `json:"description"`
+	Occurrences []string `json:"occurrences"`
+}
+
+const (
+	SchedulerTriggerAuto   = "AUTO"
+	SchedulerTriggerManual = "MANUAL"
+
+	SchedulerOutcomeSuccess = "SUCCESS"
+	SchedulerOutcomeFailed  = "FAILED"
+)
+
+// SchedulerTaskState keeps the lifecycle state of a scheduler master task
+// next to the definition owned by the stored procedures.
+type SchedulerTaskState struct {
+	TaskCode           string     `json:"task_code" gorm:"primaryKey;type:varchar(100);"`
+	Paused             bool       `json:"paused" gorm:"not null;default:false;"`
+	SkipNonWorkingDays bool       `json:"skip_non_working_days" gorm:"not null;default:false;"`
+	PausedBy           string     `json:"paused_by" gorm:"type:varchar(30);"`
+	PausedAt           *time.Time `json:"paused_at" gorm:"type:timestamp;"`
+	// AnchorAt is where the rule's occurrences are counted from, so the time
+	// of day and COUNT hold across runs. It is reset when the rule changes.
+	AnchorAt  *time.Time `json:"anchor_at" gorm:"type:timestamp;"`
+	RunCount  int        `json:"run_count" gorm:"not null;default:0;"`
+	LastRunAt *time.Time `json:"last_run_at" gorm:"type:timestamp;"`
+	NextRunAt *time.Time `json:"next_run_at" gorm:"type:timestamp;"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (SchedulerTaskState) TableName() string {
+	return "scheduler_task_state"
+}
+
+// SchedulerRunLog records every task generated from a scheduler master task.
+type SchedulerRunLog struct {
+	ID       int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	TaskCode string    `json:"task_code" gorm:"type:varchar(100);index;"`
+	TaskID   string    `json:"task_id" gorm:"type:varchar(100);"`
+	Trigger  string    `json:"trigger" gorm:"type:varchar(20);"`
+	Outcome  string    `json:"outcome" gorm:"type:varchar(20);"`
+	Message  string    `json:"message" gorm:"type:text;"`
+	RunBy    string    `json:"run_by" gorm:"type:varchar(30);"`
+	RunAt    time.Time `json:"run_at" gorm:"type:timestamp;"`
+}
+
+func (SchedulerRunLog) TableName() string {
+	return "scheduler_run_log"
+}
+
+type ParamSchedulerTaskCode struct {
+	Task_Code string `json:"task_code" form:"task_code" binding:"required"`
+	Userid    string `json:"userid" form:"userid"`
+}
+
+type UpdateSchedulerMasterTaskList struct {
+	Task_Code             string `json:"task_code" binding:"required"`
+	Skip_Non_Working_Days *bool  `json:"skip_non_working_days"`
+	InsertSchedulerMasterTaskList
+}
+
+type FetchGeneratedTaskID struct {
+	Task_ID string `json:"task_id" gorm:"varchar(30);"`
+}
+
+var QueryGetListSchedulerMasterTask = `Select * from public.getting_scheduler_master_task()` + `As ("task_code" varchar,"departemen" varchar,"subject" varchar,"task_name" varchar,"task_category" varchar,"generate_every" varchar,"priority" varchar,"estimated_time_done" varchar,"reminder_task" varchar,"assign_to" varchar,"running_at" timestamp,"next_running_at" timestamp);`
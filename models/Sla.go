@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// SlaPolicy sets the SLA targets of the tasks it matches. Empty Priority,
+// Category or Departemen match any value; the most specific active policy
+// wins. UseWorkingHours and Active are pointers so that false is saved
+// rather than replaced by the column default.
+type SlaPolicy struct {
+	ID                int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	Name              string    `json:"name" gorm:"type:varchar(100);" binding:"required"`
+	Priority          string    `json:"priority" gorm:"type:varchar(100);"`
+	Category          string    `json:"category" gorm:"type:varchar(100);"`
+	Departemen        string    `json:"departemen" gorm:"type:varchar(100);"`
+	ResponseMinutes   int       `json:"response_minutes" gorm:"not null;default:0;"`
+	ResolutionMinutes int       `json:"resolution_minutes" gorm:"not null;default:0;"`
+	WarningPercent    int       `json:"warning_percent" gorm:"not null;default:80;"`
+	UseWorkingHours   *bool     `json:"use_working_hours" gorm:"not null;default:true;"`
+	Active            *bool     `json:"active" gorm:"not null;default:true;"`
+	UpdatedBy         string    `json:"updated_by" gorm:"type:varchar(30);"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"type:timestamp;"`
+}
+
+func (SlaPolicy) TableName() string {
+	return "sla_policy"
+}
+
+// TaskSlaState is the last evaluated SLA state of a task.
+type TaskSlaState struct {
+	TaskID             string     `json:"task_id" gorm:"primaryKey;type:varchar(100);"`
+	PolicyID           int64      `json:"policy_id"`
+	ResponseDue        *time.Time `json:"response_due" gorm:"type:timestamp;"`
+	ResolutionDue      *time.Time `json:"resolution_due" gorm:"type:timestamp;"`
+	WarningAt          *time.Time `json:"warning_at" gorm:"type:timestamp;"`
+	SlaStatus          string     `json:"sla_status" gorm:"type:varchar(20);"`
+	ResponseBreached   bool       `json:"response_breached"`
+	ResolutionBreached bool       `json:"resolution_breached"`
+	EvaluatedAt        time.Time  `json:"evaluated_at" gorm:"type:timestamp;"`
+}
+
+func (TaskSlaState) TableName() string {
+	return "task_sla_state"
+}
+
+const (
+	SlaEventWarning          = "WARNING"
+	SlaEventResponseBreach   = "RESPONSE_BREACH"
+	SlaEventResolutionBreach = "RESOLUTION_BREACH"
+)
+
+// SlaBreachEvent is recorded once per task and event type.
+type SlaBreachEvent struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	TaskID     string    `json:"task_id" gorm:"type:varchar(100);uniqueIndex:idx_sla_breach_event;"`
+	EventType  string    `json:"event_type" gorm:"type:varchar(30);uniqueIndex:idx_sla_breach_event;"`
+	PolicyID   int64     `json:"policy_id"`
+	DueAt      time.Time `json:"due_at" gorm:"type:timestamp;"`
+	OccurredAt time.Time `json:"occurred_at" gorm:"type:timestamp;"`
+}
+
+func (SlaBreachEvent) TableName() string {
+	return "sla_breach_event"
+}
+
+// SlaTask is an unfinished (or just finished) task as seen by the SLA evaluator.
+type SlaTask struct {
+	Task_ID       string     `json:"task_id"`
+	Departemen    string     `json:"departemen"`
+	Priority      string     `json:"priority"`
+	Topic         string     `json:"topic"`
+	Task_Progress string     `json:"task_progress"`
+	Created_Date  time.Time  `json:"created_date"`
+	Progress_Date *time.Time `json:"progress_date"`
+	Finish_Date   *time.Time `json:"finish_date"`
+}
+
+var QueryGetOpenTaskSla = `Select * from public.get_open_task_sla() AS t("task_id" varchar,"departemen" varchar,"priority" varchar,"topic" varchar,"task_progress" varchar,"created_date" timestamp,"progress_date" timestamp,"finish_date" timestamp)`
+
+// TaskSlaFields is embedded in the task list and detail rows.
+type TaskSlaFields struct {
+	Sla_Status              string `json:"sla_status" gorm:"-"`
+	Sla_Resolution_Due      string `json:"sla_resolution_due" gorm:"-"`
+	Sla_Remaining_Minutes   *int64 `json:"sla_remaining_minutes" gorm:"-"`
+	Sla_Response_Breached   bool   `json:"sla_response_breached" gorm:"-"`
+	Sla_Resolution_Breached bool   `json:"sla_resolution_breached" gorm:"-"`
+}
+
+type ParamSlaTask struct {
+	Task_ID string `json:"task_id" form:"task_id"`
+}
+
+type ParamSlaPolicyID struct {
+	ID int64 `json:"id" binding:"required"`
+}
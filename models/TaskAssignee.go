@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	AssigneeRoleOwner       = "OWNER"
+	AssigneeRoleContributor = "CONTRIBUTOR"
+)
+
+const TaskProgressDone = "DONE"
+
+// TaskAssignee is one person working on a task. The owner is the task's
+// Assign_To; contributors are added next to it.
+type TaskAssignee struct {
+	TaskID      string     `json:"task_id" gorm:"primaryKey;type:varchar(100);"`
+	EmpNo       string     `json:"emp_no" gorm:"primaryKey;type:varchar(30);index;"`
+	EmpName     string     `json:"emp_name" gorm:"-"`
+	Role        string     `json:"role" gorm:"type:varchar(20);not null;"`
+	CompletedAt *time.Time `json:"completed_at" gorm:"type:timestamp;"`
+	AddedBy     string     `json:"added_by" gorm:"type:varchar(30);"`
+	AddedAt     time.Time  `json:"added_at" gorm:"type:timestamp;"`
+}
+
+func (TaskAssignee) TableName() string {
+	return "task_assignee"
+}
+
+// TaskCompletionRule decides whether a task with contributors is DONE once
+// all of them have completed their part, without waiting for the owner.
+type TaskCompletionRule struct {
+	TaskID                  string `json:"task_id" gorm:"primaryKey;type:varchar(100);" binding:"required"`
+	DoneWhenAllContributors bool   `json:"done_when_all_contributors"`
+}
+
+func (TaskCompletionRule) TableName() string {
+	return "task_completion_rule"
+}
+
+type ParamSavingTaskAssignee struct {
+	Task_ID  string `json:"task_id" binding:"required"`
+	Emp_No   string `json:"emp_no" binding:"required"`
+	Added_By string `json:"added_by" binding:"required"`
+}
+
+type ParamTaskAssignee struct {
+	Task_ID string `json:"task_id" binding:"required"`
+	Emp_No  string `json:"emp_no" binding:"required"`
+}
+
+type ParamMarkingCompletion struct {
+	Task_ID   string `json:"task_id" binding:"required"`
+	Userid    string `json:"userid" binding:"required"`
+	Completed bool   `json:"completed"`
+}
+
+type ValueTaskAssignees struct {
+	Assignees []TaskAssignee     `json:"assignees"`
+	Rule      TaskCompletionRule `json:"rule"`
+}
@@ -4,3 +4,9 @@ type FileUpload struct {
 	FileName string `json:"fileName" binding:"required"`
 	FilePath string `json:"filePath" binding:"required"`
 }
+
+type ParamDownloadingFile struct {
+	ObjectID string `json:"objectid" form:"objectid" binding:"required"`
+	Download bool   `json:"download" form:"download"`
+	Optimize bool   `json:"optimize" form:"optimize"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TaskEffortLog is time an employee spent on a task.
+type TaskEffortLog struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement;"`
+	TaskID    string    `json:"task_id" gorm:"type:varchar(100);index;" binding:"required"`
+	EmpNo     string    `json:"emp_no" gorm:"type:varchar(30);index;" binding:"required"`
+	Minutes   int       `json:"minutes" gorm:"not null;" binding:"required"`
+	Note      string    `json:"note" gorm:"type:varchar(255);"`
+	LoggedAt  time.Time `json:"logged_at" gorm:"type:timestamp;"`
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (TaskEffortLog) TableName() string {
+	return "task_effort_log"
+}
+
+// UserOutOfOffice is a period in which an employee is away. While it runs,
+// new assignments and notifications go to DelegateTo when one is set.
+type UserOutOfOffice struct {
+	ID                int64      `json:"id" gorm:"primaryKey;autoIncrement;"`
+	EmpNo             string     `json:"emp_no" gorm:"type:varchar(30);index;"`
+	StartDate         time.Time  `json:"start_date" gorm:"type:timestamp;"`
+	EndDate           time.Time  `json:"end_date" gorm:"type:timestamp;"`
+	Reason            string     `json:"reason" gorm:"type:varchar(255);"`
+	DelegateTo        string     `json:"delegate_to" gorm:"type:varchar(30);"`
+	HandOverOpenTasks bool       `json:"hand_over_open_tasks"`
+	HandedOverAt      *time.Time `json:"handed_over_at" gorm:"type:timestamp;"`
+	ReturnedAt        *time.Time `json:"returned_at" gorm:"type:timestamp;"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"type:timestamp;"`
+}
+
+func (UserOutOfOffice) TableName() string {
+	return "user_out_of_office"
+}
+
+type ParamAssigneeSuggestion struct {
+	Userid     string `json:"userid" form:"userid"`
+	Group      string `json:"group" form:"group"`
+	Departemen string `json:"departemen" form:"departemen"`
+}
+
+type ValueAssigneeSuggestion struct {
+	Rank        int             `json:"rank"`
+	Emp_No      string          `json:"emp_no"`
+	Emp_Name    string          `json:"emp_name"`
+	Available   bool            `json:"available"`
+	Score       float64         `json:"score"`
+	Summary     ListDataSummary `json:"summary"`
+	Explanation []string        `json:"explanation"`
+}
+
+// WorkloadTask is an open task of an assignee.
+type WorkloadTask struct {
+	Task_ID             string     `json:"task_id"`
+	Priority            string     `json:"priority"`
+	Task_Progress       string     `json:"task_progress"`
+	Estimated_Time_Done *time.Time `json:"estimated_time_done"`
+}
+
+var QueryGetOpenTaskByAssignee = `Select * from public.get_open_task_by_assignee(?) AS t("task_id" varchar,"priority" varchar,"task_progress" varchar,"estimated_time_done" timestamp)`
+
+// WorkloadAssigneeTask is an open task of one of several assignees.
+type WorkloadAssigneeTask struct {
+	User_Assign_To string `json:"user_assign_to"`
+	WorkloadTask
+}
+
+var QueryGetOpenTaskByAssignees = `Select * from public.get_open_task_by_assignees(array[?]::varchar[]) AS t("user_assign_to" varchar,"task_id" varchar,"priority" varchar,"task_progress" varchar,"estimated_time_done" timestamp)`
+
+// WorkloadSummary is the SetDataSummaryTaskList counts of one employee.
+type WorkloadSummary struct {
+	Candidate string `json:"candidate"`
+	ListDataSummary
+}
+
+// QueryGetTaskSummaries runs SetDataSummaryTaskList for several employees
+// in one statement.
+var QueryGetTaskSummaries = `Select e."candidate", s.* from unnest(array[?]::varchar[]) AS e("candidate")
+	cross join lateral public.SP_New_Version_TaskList_Universal('SetDataSummaryTaskList', e."candidate", '') AS s` + strings.TrimSuffix(ReturnTableSummary, ";")
+
+// WorkloadEffort is the effort an employee logged in a period.
+type WorkloadEffort struct {
+	Emp_No  string `json:"emp_no"`
+	Minutes int64  `json:"minutes"`
+}
+
+var QueryGetDepartmentAssignees = `Select * from public.get_department_assignees(?) AS t("emp_no" varchar,"emp_name" varchar)`
+
+var QueryGetGroupMembers = `select "emp_no","emp_name" from public."dynamic_group" where "group_name" = ? order by "emp_name"`
+
+type ParamInsertOutOfOffice struct {
+	Emp_No               string `json:"emp_no" binding:"required"`
+	Start_Date           string `json:"start_date" binding:"required"`
+	End_Date             string `json:"end_date" binding:"required"`
+	Reason               string `json:"reason"`
+	Delegate_To          string `json:"delegate_to"`
+	Hand_Over_Open_Tasks bool   `json:"hand_over_open_tasks"`
+}
+
+type ParamOutOfOffice struct {
+	Emp_No string `json:"emp_no" form:"emp_no"`
+}
+
+type ParamOutOfOfficeID struct {
+	ID int64 `json:"id" binding:"required"`
+}
+
+// TaskDelegation is a task moved to a delegate during an out-of-office
+// period, returned to its owner when the period ends.
+type TaskDelegation struct {
+	ID            int64      `json:"id" gorm:"primaryKey;autoIncrement;"`
+	OutOfOfficeID int64      `json:"out_of_office_id" gorm:"index;"`
+	TaskID        string     `json:"task_id" gorm:"type:varchar(100);index;"`
+	Owner         string     `json:"owner" gorm:"type:varchar(30);"`
+	Delegate      string     `json:"delegate" gorm:"type:varchar(30);"`
+	DelegatedAt   time.Time  `json:"delegated_at" gorm:"type:timestamp;"`
+	ReturnedAt    *time.Time `json:"returned_at" gorm:"type:timestamp;"`
+}
+
+func (TaskDelegation) TableName() string {
+	return "task_delegation"
+}